@@ -2,40 +2,191 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/felipeversiane/task-api/internal/cache"
+	"github.com/felipeversiane/task-api/internal/config"
 	"github.com/felipeversiane/task-api/internal/database"
+	"github.com/felipeversiane/task-api/internal/health"
+	"github.com/felipeversiane/task-api/internal/idempotency"
 	"github.com/felipeversiane/task-api/internal/log"
+	"github.com/felipeversiane/task-api/internal/metrics"
+	"github.com/felipeversiane/task-api/internal/outbox"
 	"github.com/felipeversiane/task-api/internal/routes"
-)
-
-var (
-	port = os.Getenv("PORT")
+	"github.com/felipeversiane/task-api/internal/stream"
+	"github.com/felipeversiane/task-api/internal/tracing"
+	"github.com/felipeversiane/task-api/internal/webhook"
 )
 
 func main() {
-	log.Configure()
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			config.Usage(os.Stderr)
+			os.Exit(0)
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	log.Configure(cfg.Log.Level)
+	slog.Info("Configuration loaded", slog.Any("config", cfg.Redacted()))
+
+	if err := run(cfg); err != nil {
+		slog.Error(fmt.Sprintf("Server stopped with error: %v", err))
+		os.Exit(1)
+	}
+}
+
+func run(cfg *config.Config) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("tracing: %w", err)
+	}
+
+	if err := database.Connect(ctx, cfg.Database); err != nil {
+		return errors.Join(err, shutdownTracing(context.Background()))
+	}
 
-	if err := database.Connect(ctx); err != nil {
-		panic(err)
+	if err := cache.Connect(cfg.Cache); err != nil {
+		database.Close()
+		return errors.Join(err, shutdownTracing(context.Background()))
 	}
-	defer database.Close()
 
-	if err := cache.Connect(); err != nil {
-		panic(err)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var workers sync.WaitGroup
+	webhookStore := webhook.NewPostgresStore(database.Connection)
+	webhooks := webhook.NewService(webhookStore)
+	publishers := []outbox.Publisher{&webhooks}
+	if cache.Client != nil {
+		publishers = append(publishers, outbox.NewStreamPublisher(cache.Client, cfg.Outbox))
+	} else {
+		slog.Warn("Outbox event stream disabled, it requires the redis cache backend")
 	}
+	relay := outbox.NewRelay(outbox.NewPostgresStore(database.Connection), cfg.Outbox, publishers...)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		relay.Run(workerCtx)
+	}()
+
+	idempotencyKeys := idempotency.NewPostgresStore(database.Connection)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		idempotencyKeys.Run(workerCtx)
+	}()
+
+	broker := stream.NewBroker(cache.Client, cfg.Stream)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		broker.Run(workerCtx)
+	}()
+
+	webhookWorker := webhook.NewWorker(webhookStore, cfg.Webhook)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		webhookWorker.Run(workerCtx)
+	}()
 
 	mux := http.NewServeMux()
-	routes.SetupRoutes(mux)
-	handler := log.LogMiddleware(mux)
+	routes.SetupRoutes(mux, broker, cfg.Webhook)
+	handler := log.RequestIDMiddleware(tracing.Middleware(mux, log.LogMiddleware(metrics.Middleware(mux))))
+
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:           handler,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+	server.RegisterOnShutdown(broker.Close)
+
+	var admin *http.Server
+	if cfg.Server.AdminAddr != "" {
+		adminMux := http.NewServeMux()
+		routes.SetupAdminRoutes(adminMux)
+		admin = &http.Server{
+			Addr:              cfg.Server.AdminAddr,
+			Handler:           adminMux,
+			ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		}
+		go func() {
+			slog.Info(fmt.Sprintf("Admin server running on %s", cfg.Server.AdminAddr))
+			if err := admin.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error(fmt.Sprintf("Admin server stopped with error: %v", err))
+			}
+		}()
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info(fmt.Sprintf("Server running on port : %d", cfg.Server.Port))
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	var errs []error
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			errs = append(errs, fmt.Errorf("listen: %w", err))
+		}
+	case <-ctx.Done():
+		slog.Info("Shutdown signal received, draining connections")
+	}
+	stop()
+	health.MarkShuttingDown()
+
+	// Keep serving while load balancers notice the failing readiness probe.
+	if len(errs) == 0 && cfg.Server.DrainDelay > 0 {
+		time.Sleep(cfg.Server.DrainDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, fmt.Errorf("http shutdown: %w", err))
+	}
+	if admin != nil {
+		if err := admin.Shutdown(shutdownCtx); err != nil {
+			errs = append(errs, fmt.Errorf("admin shutdown: %w", err))
+		}
+	}
+
+	stopWorkers()
+	workers.Wait()
+
+	database.Close()
+
+	if err := cache.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("cache close: %w", err))
+	}
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		errs = append(errs, fmt.Errorf("tracing shutdown: %w", err))
+	}
 
-	slog.Info(fmt.Sprintf("Server running on port : %s", port))
-	http.ListenAndServe(":"+port, handler)
+	slog.Info("Server stopped")
+	return errors.Join(errs...)
 }
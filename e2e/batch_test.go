@@ -0,0 +1,95 @@
+package e2e
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/felipeversiane/task-api/internal/task"
+)
+
+func batchTasks(payload map[string]interface{}, expected int, t *testing.T) map[string]interface{} {
+	api := NewApiClient()
+	resp, err := api.Post("/tasks:batch", payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	assertStatusCode(t, resp, expected)
+
+	res, err := api.ParseBody(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func batchResults(res map[string]interface{}) []map[string]interface{} {
+	var results []map[string]interface{}
+	for _, item := range res["results"].([]interface{}) {
+		results = append(results, item.(map[string]interface{}))
+	}
+	return results
+}
+
+func TestBatchTasks_ShouldContinuePastFailedStatement_WhenNotAtomic(t *testing.T) {
+	t.Log("*** Test Non-Atomic Batch with a Failing Statement")
+
+	taken := insertTaskSuccessfully(task.TaskRequest{Name: "Batch taken name", Situation: "in progress"}, t)
+	defer deleteTaskSuccessfully(taken, t)
+	renamed := insertTaskSuccessfully(task.TaskRequest{Name: "Batch renamed task", Situation: "in progress"}, t)
+	defer deleteTaskSuccessfully(renamed, t)
+
+	res := batchTasks(map[string]interface{}{
+		"operations": []map[string]interface{}{
+			{"op": "create", "name": "Batch first created", "situation": "not started"},
+			{"op": "update", "id": renamed, "name": "Batch taken name", "situation": "in progress"},
+			{"op": "create", "name": "Batch second created", "situation": "not started"},
+		},
+	}, http.StatusOK, t)
+
+	results := batchResults(res)
+	for i, expected := range []float64{http.StatusCreated, http.StatusConflict, http.StatusCreated} {
+		if results[i]["status"].(float64) != expected {
+			t.Fatalf("Expected operation %d to return %v and received %v", i, expected, results[i]["status"])
+		}
+	}
+	for _, i := range []int{0, 2} {
+		deleteTaskSuccessfully(results[i]["task"].(map[string]interface{})["id"].(string), t)
+	}
+}
+
+func TestBatchTasks_ShouldCreateAllOrNothing_WhenAtomic(t *testing.T) {
+	t.Log("*** Test Atomic Create Batch")
+
+	res := batchTasks(map[string]interface{}{
+		"atomic": true,
+		"operations": []map[string]interface{}{
+			{"op": "create", "name": "Batch atomic first", "situation": "not started"},
+			{"op": "create", "name": "Batch atomic second", "situation": "not started", "priority": "high"},
+		},
+	}, http.StatusOK, t)
+
+	for _, result := range batchResults(res) {
+		if result["status"].(float64) != http.StatusCreated {
+			t.Fatalf("Expected every operation to be created and received %v", result["status"])
+		}
+		created := result["task"].(map[string]interface{})
+		defer deleteTaskSuccessfully(created["id"].(string), t)
+		if created["created_at"].(string) == "0001-01-01T00:00:00Z" {
+			t.Fatal("Invalid CreatedAt")
+		}
+	}
+
+	res = batchTasks(map[string]interface{}{
+		"atomic": true,
+		"operations": []map[string]interface{}{
+			{"op": "create", "name": "Batch atomic third", "situation": "not started"},
+			{"op": "create", "name": "Batch atomic second", "situation": "not started"},
+		},
+	}, http.StatusConflict, t)
+
+	if message := res["message"].(string); !strings.HasPrefix(message, "operation 1:") {
+		t.Fatalf("Expected the conflict to name operation 1 and received %q", message)
+	}
+}
@@ -82,6 +82,34 @@ func (api *ApiClient) Put(path string, data map[string]interface{}) (*http.Respo
 	return resp, nil
 }
 
+func (api *ApiClient) Patch(path string, contentType string, data interface{}) (*http.Response, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := bytes.NewBuffer(body)
+	url := api.baseUrl + path
+
+	fmt.Println("PATCH", url, payload)
+
+	req, err := http.NewRequest(http.MethodPatch, url, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println("RESPONSE", resp.Status)
+
+	return resp, nil
+}
+
 func (api *ApiClient) Delete(path string) (*http.Response, error) {
 	url := api.baseUrl + path
 
@@ -104,6 +132,39 @@ func (api *ApiClient) Delete(path string) (*http.Response, error) {
 	return resp, nil
 }
 
+func (api *ApiClient) Request(method string, path string, headers map[string]string, data interface{}) (*http.Response, error) {
+	var payload io.Reader
+	if data != nil {
+		body, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		payload = bytes.NewBuffer(body)
+	}
+
+	url := api.baseUrl + path
+
+	fmt.Println(method, url, headers)
+
+	req, err := http.NewRequest(method, url, payload)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println("RESPONSE", resp.Status)
+
+	return resp, nil
+}
+
 func (api *ApiClient) ParseBody(resp *http.Response) (map[string]interface{}, error) {
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
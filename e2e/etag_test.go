@@ -0,0 +1,86 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/felipeversiane/task-api/internal/task"
+)
+
+func etagData() task.TaskRequest {
+	return task.TaskRequest{
+		Name:        "ETag task.",
+		Description: "A task edited concurrently.",
+		Situation:   "not started",
+	}
+}
+
+func getTaskETag(id string, t *testing.T) string {
+	t.Helper()
+	api := NewApiClient()
+
+	resp, err := api.Get("/tasks/" + id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	assertStatusCode(t, resp, http.StatusOK)
+
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("Missing ETag")
+	}
+	return etag
+}
+
+func TestGetTask_ShouldReturnStatusNotModified_WhenETagMatches(t *testing.T) {
+	t.Log("*** Test Get Task with If-None-Match")
+
+	api := NewApiClient()
+	id := insertTaskSuccessfully(etagData(), t)
+	defer deleteTaskSuccessfully(id, t)
+
+	etag := getTaskETag(id, t)
+
+	resp, err := api.Request(http.MethodGet, "/tasks/"+id, map[string]string{"If-None-Match": etag}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	assertStatusCode(t, resp, http.StatusNotModified)
+}
+
+func TestUpdateTask_ShouldReturnStatusPreconditionFailed_WhenETagIsStale(t *testing.T) {
+	t.Log("*** Test Update Task with stale If-Match")
+
+	api := NewApiClient()
+	data := etagData()
+	id := insertTaskSuccessfully(data, t)
+	defer deleteTaskSuccessfully(id, t)
+
+	etag := getTaskETag(id, t)
+	payload := map[string]interface{}{
+		"name":        data.Name,
+		"description": "First writer wins.",
+		"situation":   data.Situation,
+	}
+
+	resp, err := api.Request(http.MethodPut, "/tasks/"+id, map[string]string{"If-Match": etag}, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	assertStatusCode(t, resp, http.StatusOK)
+
+	if resp.Header.Get("ETag") == etag {
+		t.Fatal("ETag did not change after update")
+	}
+
+	payload["description"] = "Second writer loses."
+	resp, err = api.Request(http.MethodPut, "/tasks/"+id, map[string]string{"If-Match": etag}, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	assertStatusCode(t, resp, http.StatusPreconditionFailed)
+}
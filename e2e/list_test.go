@@ -0,0 +1,61 @@
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/felipeversiane/task-api/internal/task"
+)
+
+func TestGetAllTasks_ShouldPaginateWithCursor(t *testing.T) {
+	t.Log("*** Test Get All Tasks with Cursor Pagination")
+
+	api := NewApiClient()
+	for i := 0; i < 3; i++ {
+		id := insertTaskSuccessfully(task.TaskRequest{
+			Name:        fmt.Sprintf("Paged task %d", i),
+			Description: "A task listed page by page.",
+			Situation:   "not started",
+		}, t)
+		defer deleteTaskSuccessfully(id, t)
+	}
+
+	seen := 0
+	path := "/tasks?name_prefix=Paged%20task&sort=name&limit=2"
+	for path != "" {
+		resp, err := api.Get(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		assertStatusCode(t, resp, http.StatusOK)
+
+		res, err := api.ParseBody(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen += len(res["data"].([]interface{}))
+
+		path = ""
+		if cursor, ok := res["next_cursor"].(string); ok && cursor != "" {
+			path = "/tasks?name_prefix=Paged%20task&sort=name&limit=2&cursor=" + cursor
+		}
+	}
+
+	if seen != 3 {
+		t.Fatalf("Expected 3 tasks and received %d", seen)
+	}
+}
+
+func TestGetAllTasks_ShouldReturnStatusBadRequest_WhenSortIsInvalid(t *testing.T) {
+	t.Log("*** Test Get All Tasks with Invalid Sort")
+
+	api := NewApiClient()
+	resp, err := api.Get("/tasks?sort=description")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	assertStatusCode(t, resp, http.StatusBadRequest)
+}
@@ -0,0 +1,87 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/felipeversiane/task-api/internal/task"
+)
+
+func patchData() task.TaskRequest {
+	return task.TaskRequest{
+		Name:        "Patch task.",
+		Description: "A task to be partially updated.",
+		Situation:   "not started",
+	}
+}
+
+func TestPatchTask_ShouldApplyMergePatch(t *testing.T) {
+	t.Log("*** Test Patch Task with Merge Patch")
+
+	api := NewApiClient()
+	id := insertTaskSuccessfully(patchData(), t)
+	defer deleteTaskSuccessfully(id, t)
+
+	resp, err := api.Patch("/tasks/"+id, "application/merge-patch+json", map[string]interface{}{
+		"situation": "in progress",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	assertStatusCode(t, resp, http.StatusOK)
+
+	res, err := api.ParseBody(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res["situation"].(string) != "in progress" {
+		t.Fatal("Invalid Situation")
+	}
+	if res["name"].(string) != patchData().Name {
+		t.Fatal("Invalid Name")
+	}
+}
+
+func TestPatchTask_ShouldApplyJSONPatch(t *testing.T) {
+	t.Log("*** Test Patch Task with JSON Patch")
+
+	api := NewApiClient()
+	id := insertTaskSuccessfully(patchData(), t)
+	defer deleteTaskSuccessfully(id, t)
+
+	resp, err := api.Patch("/tasks/"+id, "application/json-patch+json", []map[string]interface{}{
+		{"op": "test", "path": "/situation", "value": "not started"},
+		{"op": "replace", "path": "/description", "value": "Patched description."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	assertStatusCode(t, resp, http.StatusOK)
+
+	res, err := api.ParseBody(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res["description"].(string) != "Patched description." {
+		t.Fatal("Invalid Description")
+	}
+}
+
+func TestPatchTask_ShouldReturnStatusUnsupportedMediaType_WhenContentTypeIsJSON(t *testing.T) {
+	t.Log("*** Test Patch Task with Unsupported Content Type")
+
+	api := NewApiClient()
+	id := insertTaskSuccessfully(patchData(), t)
+	defer deleteTaskSuccessfully(id, t)
+
+	resp, err := api.Patch("/tasks/"+id, "application/json", map[string]interface{}{
+		"situation": "in progress",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	assertStatusCode(t, resp, http.StatusUnsupportedMediaType)
+}
@@ -0,0 +1,55 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/felipeversiane/task-api/internal/task"
+)
+
+func TestSearchTasks_ShouldReturnRankedResults(t *testing.T) {
+	t.Log("*** Test Search Tasks")
+
+	api := NewApiClient()
+	id := insertTaskSuccessfully(task.TaskRequest{
+		Name:        "Searchable invoice task",
+		Description: "Reconcile the quarterly invoices.",
+		Situation:   "in progress",
+	}, t)
+	defer deleteTaskSuccessfully(id, t)
+
+	resp, err := api.Get(`/tasks/search?q=invoice+-draft+situation:%22in+progress%22`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	assertStatusCode(t, resp, http.StatusOK)
+
+	res, err := api.ParseBody(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, item := range res["data"].([]interface{}) {
+		result := item.(map[string]interface{})
+		if result["task"].(map[string]interface{})["id"].(string) == id {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("Task not found in search results")
+	}
+}
+
+func TestSearchTasks_ShouldReturnStatusBadRequest_WhenQueryIsEmpty(t *testing.T) {
+	t.Log("*** Test Search Tasks with Empty Query")
+
+	api := NewApiClient()
+	resp, err := api.Get("/tasks/search?q=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	assertStatusCode(t, resp, http.StatusBadRequest)
+}
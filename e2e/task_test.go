@@ -40,7 +40,7 @@ func TestInsertTask_ShouldReturnStatusBadRequest_WhenItHasInvalidData(t *testing
 
 }
 
-func TestInsertDuplicateTask_ShouldReturnStatusBadRequest_DuplicatedNameInDatabase(t *testing.T) {
+func TestInsertDuplicateTask_ShouldReturnStatusConflict_DuplicatedNameInDatabase(t *testing.T) {
 	t.Log("*** Test Insert Duplicate Task")
 
 	api := NewApiClient()
@@ -60,7 +60,7 @@ func TestInsertDuplicateTask_ShouldReturnStatusBadRequest_DuplicatedNameInDataba
 	}
 	defer resp.Body.Close()
 
-	assertStatusCode(t, resp, http.StatusBadRequest)
+	assertStatusCode(t, resp, http.StatusConflict)
 
 	deleteTaskSuccessfully(id, t)
 }
@@ -0,0 +1,62 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/felipeversiane/task-api/internal/task"
+)
+
+func transitionData() task.TaskRequest {
+	return task.TaskRequest{
+		Name:        "Transition task.",
+		Description: "A task that moves through its lifecycle.",
+		Situation:   "not started",
+	}
+}
+
+func transitionTask(id string, situation string, t *testing.T) *http.Response {
+	t.Helper()
+	api := NewApiClient()
+
+	resp, err := api.Post("/tasks/"+id+"/transitions", map[string]interface{}{
+		"situation": situation,
+		"reason":    "e2e transition",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestTransitionTask_ShouldFollowLifecycle(t *testing.T) {
+	t.Log("*** Test Transition Task through Lifecycle")
+
+	id := insertTaskSuccessfully(transitionData(), t)
+	defer deleteTaskSuccessfully(id, t)
+
+	for _, situation := range []string{"in progress", "completed", "reopened", "in progress"} {
+		resp := transitionTask(id, situation, t)
+		defer resp.Body.Close()
+		assertStatusCode(t, resp, http.StatusOK)
+	}
+
+	api := NewApiClient()
+	resp, err := api.Get("/tasks/" + id + "/transitions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	assertStatusCode(t, resp, http.StatusOK)
+}
+
+func TestTransitionTask_ShouldReturnStatusConflict_WhenTransitionIsIllegal(t *testing.T) {
+	t.Log("*** Test Illegal Transition Task")
+
+	id := insertTaskSuccessfully(transitionData(), t)
+	defer deleteTaskSuccessfully(id, t)
+
+	resp := transitionTask(id, "completed", t)
+	defer resp.Body.Close()
+	assertStatusCode(t, resp, http.StatusConflict)
+}
@@ -2,28 +2,39 @@ package cache
 
 import (
 	"fmt"
-	"os"
 
+	"github.com/felipeversiane/task-api/internal/config"
+	"github.com/felipeversiane/task-api/internal/tracing"
 	"github.com/redis/go-redis/v9"
 )
 
-var Client *redis.Client
+var (
+	Client   *redis.Client
+	Instance Cache
+)
 
-func Connect() error {
-	Client = redis.NewClient(&redis.Options{
-		Addr:     getConnectionString(),
-		PoolSize: 100,
-	})
+func Connect(cfg config.CacheConfig) error {
+	switch cfg.Backend {
+	case "redis":
+		Client = redis.NewClient(&redis.Options{
+			Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+			Password: cfg.Password,
+			PoolSize: cfg.PoolSize,
+		})
+		Client.AddHook(tracing.RedisHook{})
+		Instance = NewRedisCache(Client)
+	case "memory":
+		Instance = NewLRUCache(cfg.Size)
+	default:
+		return fmt.Errorf("unknown cache backend %q", cfg.Backend)
+	}
 
 	return nil
 }
 
-func getConnectionString() string {
-	host := os.Getenv("REDIS_HOST")
-	port := os.Getenv("REDIS_PORT")
-	password := os.Getenv("REDIS_PASSWORD")
-	if password != "" {
-		return fmt.Sprintf("%s:%s@%s:%s", password, host, host, port)
+func Close() error {
+	if Client == nil {
+		return nil
 	}
-	return fmt.Sprintf("%s:%s", host, port)
+	return Client.Close()
 }
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLRUCache_ShouldEvictLeastRecentlyUsed_WhenCapacityIsExceeded(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRUCache(2)
+
+	c.Set(ctx, "a", []byte("1"), 0)
+	c.Set(ctx, "b", []byte("2"), 0)
+	if _, err := c.Get(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	c.Set(ctx, "c", []byte("3"), 0)
+
+	if _, err := c.Get(ctx, "b"); !errors.Is(err, ErrMiss) {
+		t.Fatalf("Expected b to be evicted and received %v", err)
+	}
+	if value, err := c.Get(ctx, "a"); err != nil || string(value) != "1" {
+		t.Fatalf("Expected a to be kept and received %q, %v", value, err)
+	}
+	if c.Len() != 2 {
+		t.Fatalf("Expected 2 entries and received %d", c.Len())
+	}
+}
+
+func TestLRUCache_ShouldMiss_WhenEntryIsExpired(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRUCache(10)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.Set(ctx, "a", []byte("1"), time.Minute)
+	if _, err := c.Get(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := c.Get(ctx, "a"); !errors.Is(err, ErrMiss) {
+		t.Fatalf("Expected a to be expired and received %v", err)
+	}
+}
+
+func TestLRUCache_ShouldDeleteKeys(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRUCache(10)
+
+	c.Set(ctx, "a", []byte("1"), 0)
+	c.Set(ctx, "b", []byte("2"), 0)
+	c.Del(ctx, "a", "b", "missing")
+
+	if c.Len() != 0 {
+		t.Fatalf("Expected empty cache and received %d entries", c.Len())
+	}
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const redacted = "*****"
+
+type Config struct {
+	Server   ServerConfig   `yaml:"server" toml:"server"`
+	Database DatabaseConfig `yaml:"database" toml:"database"`
+	Cache    CacheConfig    `yaml:"cache" toml:"cache"`
+	Log      LogConfig      `yaml:"log" toml:"log"`
+	Tracing  TracingConfig  `yaml:"tracing" toml:"tracing"`
+	Outbox   OutboxConfig   `yaml:"outbox" toml:"outbox"`
+	Webhook  WebhookConfig  `yaml:"webhook" toml:"webhook"`
+	Stream   StreamConfig   `yaml:"stream" toml:"stream"`
+}
+
+type ServerConfig struct {
+	Port              int           `yaml:"port" toml:"port"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" toml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" toml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	DrainDelay        time.Duration `yaml:"drain_delay" toml:"drain_delay"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" toml:"max_header_bytes"`
+	AdminAddr         string        `yaml:"admin_addr" toml:"admin_addr"`
+}
+
+type DatabaseConfig struct {
+	Host           string        `yaml:"host" toml:"host"`
+	Port           int           `yaml:"port" toml:"port"`
+	User           string        `yaml:"user" toml:"user"`
+	Password       string        `yaml:"password" toml:"password"`
+	Name           string        `yaml:"name" toml:"name"`
+	SSLMode        string        `yaml:"sslmode" toml:"sslmode"`
+	ConnectTimeout time.Duration `yaml:"connect_timeout" toml:"connect_timeout"`
+}
+
+type CacheConfig struct {
+	Backend  string `yaml:"backend" toml:"backend"`
+	Host     string `yaml:"host" toml:"host"`
+	Port     int    `yaml:"port" toml:"port"`
+	Password string `yaml:"password" toml:"password"`
+	PoolSize int    `yaml:"pool_size" toml:"pool_size"`
+	Size     int    `yaml:"size" toml:"size"`
+}
+
+type LogConfig struct {
+	Level string `yaml:"level" toml:"level"`
+}
+
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" toml:"exporter"`
+	Endpoint    string  `yaml:"endpoint" toml:"endpoint"`
+	File        string  `yaml:"file" toml:"file"`
+	ServiceName string  `yaml:"service_name" toml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio"`
+}
+
+type OutboxConfig struct {
+	Stream       string        `yaml:"stream" toml:"stream"`
+	MaxLen       int           `yaml:"max_len" toml:"max_len"`
+	PollInterval time.Duration `yaml:"poll_interval" toml:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size" toml:"batch_size"`
+	Retention    time.Duration `yaml:"retention" toml:"retention"`
+}
+
+type WebhookConfig struct {
+	MaxAttempts          int           `yaml:"max_attempts" toml:"max_attempts"`
+	InitialBackoff       time.Duration `yaml:"initial_backoff" toml:"initial_backoff"`
+	MaxBackoff           time.Duration `yaml:"max_backoff" toml:"max_backoff"`
+	PollInterval         time.Duration `yaml:"poll_interval" toml:"poll_interval"`
+	Timeout              time.Duration `yaml:"timeout" toml:"timeout"`
+	BatchSize            int           `yaml:"batch_size" toml:"batch_size"`
+	Concurrency          int           `yaml:"concurrency" toml:"concurrency"`
+	AllowPrivateNetworks bool          `yaml:"allow_private_networks" toml:"allow_private_networks"`
+}
+
+type StreamConfig struct {
+	Channel    string        `yaml:"channel" toml:"channel"`
+	BufferSize int           `yaml:"buffer_size" toml:"buffer_size"`
+	Heartbeat  time.Duration `yaml:"heartbeat" toml:"heartbeat"`
+}
+
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:              8000,
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   15 * time.Second,
+			DrainDelay:        5 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+			AdminAddr:         "localhost:6060",
+		},
+		Database: DatabaseConfig{
+			Host:           "localhost",
+			Port:           5432,
+			User:           "postgres",
+			Name:           "postgres",
+			SSLMode:        "disable",
+			ConnectTimeout: 10 * time.Second,
+		},
+		Cache: CacheConfig{
+			Backend:  "redis",
+			Host:     "localhost",
+			Port:     6379,
+			PoolSize: 100,
+			Size:     10000,
+		},
+		Log: LogConfig{
+			Level: "INFO",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "task-api",
+			SampleRatio: 1,
+		},
+		Outbox: OutboxConfig{
+			Stream:       "tasks.events",
+			MaxLen:       100000,
+			PollInterval: time.Second,
+			BatchSize:    100,
+			Retention:    7 * 24 * time.Hour,
+		},
+		Webhook: WebhookConfig{
+			MaxAttempts:    8,
+			InitialBackoff: 10 * time.Second,
+			MaxBackoff:     time.Hour,
+			PollInterval:   time.Second,
+			Timeout:        10 * time.Second,
+			BatchSize:      50,
+			Concurrency:    10,
+		},
+		Stream: StreamConfig{
+			Channel:    "tasks.changes",
+			BufferSize: 1000,
+			Heartbeat:  15 * time.Second,
+		},
+	}
+}
+
+func (c Config) String() string {
+	var b strings.Builder
+	for _, f := range c.fields() {
+		value := f.value.String()
+		if f.secret && value != "" {
+			value = redacted
+		}
+		fmt.Fprintf(&b, "%s=%s\n", f.name, value)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (c Config) Redacted() map[string]string {
+	values := map[string]string{}
+	for _, f := range c.fields() {
+		value := f.value.String()
+		if f.secret && value != "" {
+			value = redacted
+		}
+		values[f.name] = value
+	}
+	return values
+}
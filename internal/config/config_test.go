@@ -0,0 +1,106 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fakeEnv(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+func fakeFile(content string) func(string) ([]byte, error) {
+	return func(string) ([]byte, error) {
+		return []byte(content), nil
+	}
+}
+
+func TestLoad_ShouldApplySourcesInPrecedenceOrder(t *testing.T) {
+	file := `
+server:
+  port: 9000
+  shutdown_timeout: 5s
+database:
+  host: file-db
+  user: file-user
+cache:
+  backend: memory
+`
+	env := fakeEnv(map[string]string{
+		"POSTGRES_HOST": "env-db",
+		"PORT":          "9001",
+	})
+
+	cfg, err := load([]string{"-config", "app.yaml", "-server.port", "9002"}, env, fakeFile(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Server.Port != 9002 {
+		t.Fatalf("Expected flag port 9002 and received %d", cfg.Server.Port)
+	}
+	if cfg.Database.Host != "env-db" {
+		t.Fatalf("Expected env host and received %q", cfg.Database.Host)
+	}
+	if cfg.Database.User != "file-user" || cfg.Cache.Backend != "memory" {
+		t.Fatalf("Expected file values and received %+v", cfg)
+	}
+	if cfg.Server.ShutdownTimeout != 5*time.Second {
+		t.Fatalf("Expected file shutdown timeout and received %s", cfg.Server.ShutdownTimeout)
+	}
+	if cfg.Server.ReadHeaderTimeout != Default().Server.ReadHeaderTimeout {
+		t.Fatalf("Expected default read header timeout and received %s", cfg.Server.ReadHeaderTimeout)
+	}
+}
+
+func TestLoad_ShouldReadTOMLFile(t *testing.T) {
+	file := `
+[database]
+name = "tasks"
+`
+	cfg, err := load([]string{"-config", "app.toml"}, fakeEnv(nil), fakeFile(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.Name != "tasks" {
+		t.Fatalf("Expected toml database name and received %q", cfg.Database.Name)
+	}
+}
+
+func TestLoad_ShouldReportEveryValidationProblem(t *testing.T) {
+	env := fakeEnv(map[string]string{
+		"PORT":          "70000",
+		"CACHE_BACKEND": "memcached",
+		"LOG_LEVEL":     "verbose",
+	})
+
+	_, err := load(nil, env, os.ReadFile)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected validation error and received %v", err)
+	}
+	if len(validationErr.Problems) != 3 {
+		t.Fatalf("Expected 3 problems and received %v", validationErr)
+	}
+}
+
+func TestConfigString_ShouldRedactSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.Database.Password = "Pass23982"
+	cfg.Cache.Password = "hunter2"
+
+	printed := cfg.String()
+	if strings.Contains(printed, "Pass23982") || strings.Contains(printed, "hunter2") {
+		t.Fatalf("Secrets leaked in %s", printed)
+	}
+	if cfg.Redacted()["database.password"] != redacted {
+		t.Fatal("Expected database password to be redacted")
+	}
+}
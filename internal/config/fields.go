@@ -0,0 +1,129 @@
+package config
+
+import (
+	"strconv"
+	"time"
+)
+
+type field struct {
+	name   string
+	env    string
+	usage  string
+	secret bool
+	value  fieldValue
+}
+
+type fieldValue interface {
+	String() string
+	Set(string) error
+}
+
+type stringValue struct{ p *string }
+
+func (v stringValue) String() string { return *v.p }
+
+func (v stringValue) Set(s string) error {
+	*v.p = s
+	return nil
+}
+
+type intValue struct{ p *int }
+
+func (v intValue) String() string { return strconv.Itoa(*v.p) }
+
+func (v intValue) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*v.p = n
+	return nil
+}
+
+type floatValue struct{ p *float64 }
+
+func (v floatValue) String() string { return strconv.FormatFloat(*v.p, 'g', -1, 64) }
+
+func (v floatValue) Set(s string) error {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	*v.p = f
+	return nil
+}
+
+type boolValue struct{ p *bool }
+
+func (v boolValue) String() string { return strconv.FormatBool(*v.p) }
+
+func (v boolValue) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*v.p = b
+	return nil
+}
+
+type durationValue struct{ p *time.Duration }
+
+func (v durationValue) String() string { return v.p.String() }
+
+func (v durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*v.p = d
+	return nil
+}
+
+func (c *Config) fields() []field {
+	return []field{
+		{name: "server.port", env: "PORT", usage: "HTTP listen port", value: intValue{&c.Server.Port}},
+		{name: "server.read-timeout", env: "SERVER_READ_TIMEOUT", usage: "maximum duration for reading a request", value: durationValue{&c.Server.ReadTimeout}},
+		{name: "server.read-header-timeout", env: "SERVER_READ_HEADER_TIMEOUT", usage: "maximum duration for reading request headers", value: durationValue{&c.Server.ReadHeaderTimeout}},
+		{name: "server.write-timeout", env: "SERVER_WRITE_TIMEOUT", usage: "maximum duration before timing out writes of the response", value: durationValue{&c.Server.WriteTimeout}},
+		{name: "server.idle-timeout", env: "SERVER_IDLE_TIMEOUT", usage: "maximum time to wait for the next request on keep-alive connections", value: durationValue{&c.Server.IdleTimeout}},
+		{name: "server.shutdown-timeout", env: "SHUTDOWN_TIMEOUT", usage: "maximum time to drain in-flight requests on shutdown", value: durationValue{&c.Server.ShutdownTimeout}},
+		{name: "server.drain-delay", env: "DRAIN_DELAY", usage: "time to keep serving after readiness fails on shutdown", value: durationValue{&c.Server.DrainDelay}},
+		{name: "server.max-header-bytes", env: "SERVER_MAX_HEADER_BYTES", usage: "maximum size of request headers", value: intValue{&c.Server.MaxHeaderBytes}},
+		{name: "server.admin-addr", env: "ADMIN_ADDR", usage: "listen address of the internal admin server, empty to disable", value: stringValue{&c.Server.AdminAddr}},
+		{name: "database.host", env: "POSTGRES_HOST", usage: "PostgreSQL host", value: stringValue{&c.Database.Host}},
+		{name: "database.port", env: "POSTGRES_PORT", usage: "PostgreSQL port", value: intValue{&c.Database.Port}},
+		{name: "database.user", env: "POSTGRES_USER", usage: "PostgreSQL user", value: stringValue{&c.Database.User}},
+		{name: "database.password", env: "POSTGRES_PASSWORD", usage: "PostgreSQL password", secret: true, value: stringValue{&c.Database.Password}},
+		{name: "database.name", env: "POSTGRES_DB", usage: "PostgreSQL database name", value: stringValue{&c.Database.Name}},
+		{name: "database.sslmode", env: "POSTGRES_SSLMODE", usage: "PostgreSQL sslmode", value: stringValue{&c.Database.SSLMode}},
+		{name: "database.connect-timeout", env: "POSTGRES_CONNECT_TIMEOUT", usage: "PostgreSQL connect timeout", value: durationValue{&c.Database.ConnectTimeout}},
+		{name: "cache.backend", env: "CACHE_BACKEND", usage: "cache backend (redis or memory)", value: stringValue{&c.Cache.Backend}},
+		{name: "cache.host", env: "REDIS_HOST", usage: "Redis host", value: stringValue{&c.Cache.Host}},
+		{name: "cache.port", env: "REDIS_PORT", usage: "Redis port", value: intValue{&c.Cache.Port}},
+		{name: "cache.password", env: "REDIS_PASSWORD", usage: "Redis password", secret: true, value: stringValue{&c.Cache.Password}},
+		{name: "cache.pool-size", env: "REDIS_POOL_SIZE", usage: "Redis connection pool size", value: intValue{&c.Cache.PoolSize}},
+		{name: "cache.size", env: "CACHE_SIZE", usage: "maximum entries for the memory cache backend", value: intValue{&c.Cache.Size}},
+		{name: "log.level", env: "LOG_LEVEL", usage: "log level (DEBUG, INFO, WARN, ERROR)", value: stringValue{&c.Log.Level}},
+		{name: "tracing.exporter", env: "TRACING_EXPORTER", usage: "trace exporter (none, stdout, file or otlp)", value: stringValue{&c.Tracing.Exporter}},
+		{name: "tracing.endpoint", env: "OTEL_EXPORTER_OTLP_ENDPOINT", usage: "OTLP/HTTP collector endpoint", value: stringValue{&c.Tracing.Endpoint}},
+		{name: "tracing.file", env: "TRACING_FILE", usage: "path written by the file trace exporter", value: stringValue{&c.Tracing.File}},
+		{name: "tracing.service-name", env: "OTEL_SERVICE_NAME", usage: "service name reported on spans", value: stringValue{&c.Tracing.ServiceName}},
+		{name: "tracing.sample-ratio", env: "TRACING_SAMPLE_RATIO", usage: "fraction of new traces to sample (0 to 1)", value: floatValue{&c.Tracing.SampleRatio}},
+		{name: "outbox.stream", env: "OUTBOX_STREAM", usage: "Redis stream that receives task change events", value: stringValue{&c.Outbox.Stream}},
+		{name: "outbox.max-len", env: "OUTBOX_STREAM_MAX_LEN", usage: "approximate maximum length of the event stream", value: intValue{&c.Outbox.MaxLen}},
+		{name: "outbox.poll-interval", env: "OUTBOX_POLL_INTERVAL", usage: "how often the relay polls for unpublished events", value: durationValue{&c.Outbox.PollInterval}},
+		{name: "outbox.batch-size", env: "OUTBOX_BATCH_SIZE", usage: "maximum events published per relay round", value: intValue{&c.Outbox.BatchSize}},
+		{name: "outbox.retention", env: "OUTBOX_RETENTION", usage: "how long published events are kept in the outbox table", value: durationValue{&c.Outbox.Retention}},
+		{name: "webhook.max-attempts", env: "WEBHOOK_MAX_ATTEMPTS", usage: "failed attempts before a webhook delivery is dead-lettered", value: intValue{&c.Webhook.MaxAttempts}},
+		{name: "webhook.initial-backoff", env: "WEBHOOK_INITIAL_BACKOFF", usage: "delay before the first webhook retry, doubled on each failure", value: durationValue{&c.Webhook.InitialBackoff}},
+		{name: "webhook.max-backoff", env: "WEBHOOK_MAX_BACKOFF", usage: "upper bound for the webhook retry delay", value: durationValue{&c.Webhook.MaxBackoff}},
+		{name: "webhook.poll-interval", env: "WEBHOOK_POLL_INTERVAL", usage: "how often the webhook worker polls for due deliveries", value: durationValue{&c.Webhook.PollInterval}},
+		{name: "webhook.timeout", env: "WEBHOOK_TIMEOUT", usage: "timeout for a single webhook request", value: durationValue{&c.Webhook.Timeout}},
+		{name: "webhook.batch-size", env: "WEBHOOK_BATCH_SIZE", usage: "maximum deliveries attempted per worker round", value: intValue{&c.Webhook.BatchSize}},
+		{name: "webhook.concurrency", env: "WEBHOOK_CONCURRENCY", usage: "webhook deliveries attempted at the same time", value: intValue{&c.Webhook.Concurrency}},
+		{name: "webhook.allow-private-networks", env: "WEBHOOK_ALLOW_PRIVATE_NETWORKS", usage: "allow webhook receivers on loopback, private and link-local addresses", value: boolValue{&c.Webhook.AllowPrivateNetworks}},
+		{name: "stream.channel", env: "STREAM_CHANNEL", usage: "Redis Pub/Sub channel that fans task changes out to every replica", value: stringValue{&c.Stream.Channel}},
+		{name: "stream.buffer-size", env: "STREAM_BUFFER_SIZE", usage: "task changes kept for Last-Event-ID resume", value: intValue{&c.Stream.BufferSize}},
+		{name: "stream.heartbeat", env: "STREAM_HEARTBEAT", usage: "interval between keep-alive comments on idle event streams", value: durationValue{&c.Stream.Heartbeat}},
+	}
+}
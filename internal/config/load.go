@@ -0,0 +1,108 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const configFileEnv = "CONFIG_FILE"
+
+func Load(args []string) (*Config, error) {
+	return load(args, os.LookupEnv, os.ReadFile)
+}
+
+func load(args []string, lookupEnv func(string) (string, bool), readFile func(string) ([]byte, error)) (*Config, error) {
+	var path string
+	probe := Default()
+	fs := newFlagSet(&probe, &path, io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if path == "" {
+		path, _ = lookupEnv(configFileEnv)
+	}
+
+	cfg := Default()
+
+	if path != "" {
+		data, err := readFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read config file: %w", err)
+		}
+		if err := decodeFile(path, data, &cfg); err != nil {
+			return nil, fmt.Errorf("parse config file %s: %w", path, err)
+		}
+	}
+
+	var errs []error
+	for _, f := range cfg.fields() {
+		value, ok := lookupEnv(f.env)
+		if !ok || value == "" {
+			continue
+		}
+		if err := f.value.Set(value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid value %q for %s", f.name, value, f.env))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, &ValidationError{Problems: errs}
+	}
+
+	if err := newFlagSet(&cfg, &path, io.Discard).Parse(args); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func Usage(w io.Writer) {
+	var path string
+	cfg := Default()
+	fs := newFlagSet(&cfg, &path, w)
+	fs.PrintDefaults()
+}
+
+func newFlagSet(cfg *Config, path *string, output io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("task-api", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(path, "config", "", "path to a YAML or TOML configuration file (env "+configFileEnv+")")
+	for _, f := range cfg.fields() {
+		fs.Var(f.value, f.name, fmt.Sprintf("%s (env %s)", f.usage, f.env))
+	}
+	return fs
+}
+
+func decodeFile(path string, data []byte, cfg *Config) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		return nil
+	case ".toml":
+		meta, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return err
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("unknown keys: %v", undecoded)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported config file extension %q", filepath.Ext(path))
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+var logLevels = map[string]bool{
+	"DEBUG": true,
+	"INFO":  true,
+	"WARN":  true,
+	"ERROR": true,
+}
+
+var cacheBackends = map[string]bool{
+	"redis":  true,
+	"memory": true,
+}
+
+var traceExporters = map[string]bool{
+	"none":   true,
+	"stdout": true,
+	"file":   true,
+	"otlp":   true,
+}
+
+type ValidationError struct {
+	Problems []error
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid configuration:")
+	for _, problem := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(problem.Error())
+	}
+	return b.String()
+}
+
+func (c *Config) Validate() error {
+	var problems []error
+	check := func(ok bool, name string, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Errorf("%s: %s", name, fmt.Sprintf(format, args...)))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port", "must be between 1 and 65535, got %d", c.Server.Port)
+	check(c.Server.ReadTimeout > 0, "server.read-timeout", "must be positive")
+	check(c.Server.ReadHeaderTimeout > 0, "server.read-header-timeout", "must be positive")
+	check(c.Server.WriteTimeout > 0, "server.write-timeout", "must be positive")
+	check(c.Server.IdleTimeout > 0, "server.idle-timeout", "must be positive")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown-timeout", "must be positive")
+	check(c.Server.DrainDelay >= 0, "server.drain-delay", "must not be negative")
+	check(c.Server.MaxHeaderBytes > 0, "server.max-header-bytes", "must be positive")
+
+	check(c.Database.Host != "", "database.host", "is required")
+	check(c.Database.Port > 0 && c.Database.Port <= 65535, "database.port", "must be between 1 and 65535, got %d", c.Database.Port)
+	check(c.Database.User != "", "database.user", "is required")
+	check(c.Database.Name != "", "database.name", "is required")
+	check(c.Database.ConnectTimeout > 0, "database.connect-timeout", "must be positive")
+
+	check(cacheBackends[c.Cache.Backend], "cache.backend", "must be one of redis, memory, got %q", c.Cache.Backend)
+	if c.Cache.Backend == "redis" {
+		check(c.Cache.Host != "", "cache.host", "is required for the redis backend")
+		check(c.Cache.Port > 0 && c.Cache.Port <= 65535, "cache.port", "must be between 1 and 65535, got %d", c.Cache.Port)
+		check(c.Cache.PoolSize > 0, "cache.pool-size", "must be positive")
+	}
+	if c.Cache.Backend == "memory" {
+		check(c.Cache.Size > 0, "cache.size", "must be positive")
+	}
+
+	check(logLevels[strings.ToUpper(c.Log.Level)], "log.level", "must be one of DEBUG, INFO, WARN, ERROR, got %q", c.Log.Level)
+
+	check(traceExporters[c.Tracing.Exporter], "tracing.exporter", "must be one of none, stdout, file, otlp, got %q", c.Tracing.Exporter)
+	if c.Tracing.Exporter == "file" {
+		check(c.Tracing.File != "", "tracing.file", "is required for the file exporter")
+	}
+	if c.Tracing.Exporter != "none" {
+		check(c.Tracing.ServiceName != "", "tracing.service-name", "is required")
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample-ratio", "must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+
+	check(c.Outbox.Stream != "", "outbox.stream", "is required")
+	check(c.Outbox.MaxLen > 0, "outbox.max-len", "must be positive")
+	check(c.Outbox.PollInterval > 0, "outbox.poll-interval", "must be positive")
+	check(c.Outbox.BatchSize > 0, "outbox.batch-size", "must be positive")
+	check(c.Outbox.Retention > 0, "outbox.retention", "must be positive")
+
+	check(c.Webhook.MaxAttempts > 0, "webhook.max-attempts", "must be positive")
+	check(c.Webhook.InitialBackoff > 0, "webhook.initial-backoff", "must be positive")
+	check(c.Webhook.MaxBackoff >= c.Webhook.InitialBackoff, "webhook.max-backoff", "must not be lower than webhook.initial-backoff")
+	check(c.Webhook.PollInterval > 0, "webhook.poll-interval", "must be positive")
+	check(c.Webhook.Timeout > 0, "webhook.timeout", "must be positive")
+	check(c.Webhook.BatchSize > 0, "webhook.batch-size", "must be positive")
+	check(c.Webhook.Concurrency > 0, "webhook.concurrency", "must be positive")
+
+	check(c.Stream.Channel != "", "stream.channel", "is required")
+	check(c.Stream.BufferSize > 0, "stream.buffer-size", "must be positive")
+	check(c.Stream.Heartbeat > 0, "stream.heartbeat", "must be positive")
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
@@ -3,25 +3,27 @@ package database
 import (
 	"context"
 	"fmt"
-	"os"
-	"time"
+	"strings"
 
+	"github.com/felipeversiane/task-api/internal/config"
+	"github.com/felipeversiane/task-api/internal/tracing"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var Connection *pgxpool.Pool
 
-func Connect(ctx context.Context) error {
-	dsn := getConnectionString()
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+func Connect(ctx context.Context, cfg config.DatabaseConfig) error {
+	dsn := getConnectionString(cfg)
+	ctx, cancel := context.WithTimeout(ctx, cfg.ConnectTimeout)
 	defer cancel()
 
-	cfg, err := pgxpool.ParseConfig(dsn)
+	poolConfig, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return err
 	}
+	poolConfig.ConnConfig.Tracer = tracing.QueryTracer{}
 
-	Connection, err = pgxpool.NewWithConfig(ctx, cfg)
+	Connection, err = pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return err
 	}
@@ -35,14 +37,12 @@ func Close() {
 	Connection.Close()
 }
 
-func getConnectionString() string {
-	user := os.Getenv("POSTGRES_USER")
-	password := os.Getenv("POSTGRES_PASSWORD")
-	dbname := os.Getenv("POSTGRES_DB")
-	dbport := os.Getenv("POSTGRES_PORT")
-	dbhost := os.Getenv("POSTGRES_HOST")
-
-	dsn := fmt.Sprintf("user=%s password=%s dbname=%s port=%s host=%s sslmode=disable", user, password, dbname, dbport, dbhost)
+func getConnectionString(cfg config.DatabaseConfig) string {
+	return fmt.Sprintf("user=%s password=%s dbname=%s port=%d host=%s sslmode=%s",
+		quote(cfg.User), quote(cfg.Password), quote(cfg.Name), cfg.Port, quote(cfg.Host), quote(cfg.SSLMode))
+}
 
-	return dsn
+func quote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", `\'`) + "'"
 }
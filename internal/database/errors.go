@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+
+	domain "github.com/felipeversiane/task-api/internal"
+	"github.com/felipeversiane/task-api/internal/rest"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	UniqueViolation          = "23505"
+	CheckViolation           = "23514"
+	NotNullViolation         = "23502"
+	StringDataRightTruncated = "22001"
+	SerializationFailure     = "40001"
+	DeadlockDetected         = "40P01"
+	QueryCanceled            = "57014"
+	TooManyConnections       = "53300"
+	CannotConnectNow         = "57P03"
+)
+
+var checkConstraints = map[string]domain.FieldError{
+	"tasks_situation_check": {Field: "situation", Code: domain.CodeInvalidEnum, Message: "invalid situation value"},
+	"tasks_priority_check":  {Field: "priority", Code: domain.CodeInvalidEnum, Message: "priority must be one of low, medium, high, urgent"},
+}
+
+var requiredColumns = map[string]domain.FieldError{
+	"tasks.name":                {Field: "name", Code: domain.CodeRequired, Message: "name cannot be empty"},
+	"tasks.situation":           {Field: "situation", Code: domain.CodeRequired, Message: "situation cannot be empty"},
+	"tags.name":                 {Field: "name", Code: domain.CodeRequired, Message: "tag name is required"},
+	"webhook_subscriptions.url": {Field: "url", Code: domain.CodeRequired, Message: "url is required"},
+}
+
+func MapError(err error) *rest.RestError {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return rest.NewNotFoundError("resource not found")
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case UniqueViolation:
+			return rest.NewConflictError("resource already exists")
+		case CheckViolation, NotNullViolation, StringDataRightTruncated:
+			return constraintError(pgErr)
+		case SerializationFailure, DeadlockDetected:
+			return rest.NewServiceUnavailableError("concurrent update could not be serialized, retry the request")
+		case QueryCanceled:
+			return rest.NewServiceUnavailableError("database query was canceled")
+		case TooManyConnections, CannotConnectNow:
+			return rest.NewServiceUnavailableError("database is unavailable")
+		}
+		return internalError(err)
+	}
+
+	if unavailable(err) {
+		return rest.NewServiceUnavailableError("database is unavailable")
+	}
+	return internalError(err)
+}
+
+func constraintError(pgErr *pgconn.PgError) *rest.RestError {
+	var fieldErr domain.FieldError
+	var ok bool
+	switch pgErr.Code {
+	case CheckViolation:
+		fieldErr, ok = checkConstraints[pgErr.ConstraintName]
+	case NotNullViolation:
+		fieldErr, ok = requiredColumns[pgErr.TableName+"."+pgErr.ColumnName]
+	}
+	if !ok {
+		slog.Warn("Unmapped database constraint violation", slog.Any("error", pgErr))
+		return rest.NewUnprocessableEntityError("request violates a data constraint")
+	}
+	return rest.ValidationError(domain.ValidationErrors{fieldErr})
+}
+
+func internalError(err error) *rest.RestError {
+	slog.Error("Database operation failed", slog.Any("error", err))
+	return rest.NewInternalServerError("internal database error")
+}
+
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == UniqueViolation
+}
+
+func unavailable(err error) bool {
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	return errors.As(err, &connectErr) ||
+		errors.As(err, &netErr) ||
+		pgconn.Timeout(err) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestMapError_ShouldTranslateSQLState(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		code int
+	}{
+		"unique violation":      {&pgconn.PgError{Code: UniqueViolation}, http.StatusConflict},
+		"check violation":       {fmt.Errorf("insert: %w", &pgconn.PgError{Code: CheckViolation}), http.StatusUnprocessableEntity},
+		"serialization failure": {&pgconn.PgError{Code: SerializationFailure}, http.StatusServiceUnavailable},
+		"query canceled":        {&pgconn.PgError{Code: QueryCanceled}, http.StatusServiceUnavailable},
+		"unknown sqlstate":      {&pgconn.PgError{Code: "XX000"}, http.StatusInternalServerError},
+		"deadline exceeded":     {context.DeadlineExceeded, http.StatusServiceUnavailable},
+		"unexpected error":      {errors.New("boom"), http.StatusInternalServerError},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := MapError(c.err); got.Code != c.code {
+				t.Fatalf("Expected status %d and received %d (%s)", c.code, got.Code, got.Message)
+			}
+		})
+	}
+}
+
+func TestMapError_ShouldNotExposeDatabaseMessages(t *testing.T) {
+	check := MapError(&pgconn.PgError{
+		Code:           CheckViolation,
+		ConstraintName: "tasks_priority_check",
+		Message:        `new row for relation "tasks" violates check constraint "tasks_priority_check"`,
+	})
+	if check.Code != http.StatusBadRequest || len(check.Causes) != 1 || check.Causes[0].Field != "priority" {
+		t.Fatalf("Expected a priority field error and received %+v", check)
+	}
+
+	cases := map[string]error{
+		"unknown constraint": &pgconn.PgError{Code: CheckViolation, ConstraintName: "tasks_secret_check", Message: `violates check constraint "tasks_secret_check"`},
+		"unknown sqlstate":   &pgconn.PgError{Code: "XX000", Message: `relation "tasks" is corrupted`},
+		"unexpected error":   errors.New(`scan column "tasks.secret": boom`),
+	}
+	for name, err := range cases {
+		if got := MapError(err); strings.Contains(got.Message, "tasks") {
+			t.Fatalf("%s: expected a generic message and received %q", name, got.Message)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package database
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func RegisterMetrics(registry prometheus.Registerer) {
+	stat := func(fn func() float64) func() float64 {
+		return func() float64 {
+			if Connection == nil {
+				return 0
+			}
+			return fn()
+		}
+	}
+
+	registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: "pgxpool_acquired_conns", Help: "Number of currently acquired connections in the pool."},
+			stat(func() float64 { return float64(Connection.Stat().AcquiredConns()) })),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: "pgxpool_idle_conns", Help: "Number of currently idle connections in the pool."},
+			stat(func() float64 { return float64(Connection.Stat().IdleConns()) })),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: "pgxpool_total_conns", Help: "Total number of connections currently in the pool."},
+			stat(func() float64 { return float64(Connection.Stat().TotalConns()) })),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: "pgxpool_max_conns", Help: "Maximum size of the pool."},
+			stat(func() float64 { return float64(Connection.Stat().MaxConns()) })),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{Name: "pgxpool_acquire_total", Help: "Cumulative count of successful acquires from the pool."},
+			stat(func() float64 { return float64(Connection.Stat().AcquireCount()) })),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{Name: "pgxpool_empty_acquire_total", Help: "Cumulative count of acquires that waited for a connection."},
+			stat(func() float64 { return float64(Connection.Stat().EmptyAcquireCount()) })),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{Name: "pgxpool_acquire_wait_seconds_total", Help: "Total time spent waiting to acquire connections."},
+			stat(func() float64 { return Connection.Stat().AcquireDuration().Seconds() })),
+	)
+}
@@ -0,0 +1,66 @@
+package health
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+type PostgresPoolStats struct {
+	TotalConns      int32  `json:"total_conns"`
+	AcquiredConns   int32  `json:"acquired_conns"`
+	IdleConns       int32  `json:"idle_conns"`
+	MaxConns        int32  `json:"max_conns"`
+	AcquireCount    int64  `json:"acquire_count"`
+	AcquireDuration string `json:"acquire_duration"`
+}
+
+type RedisPoolStats struct {
+	Hits       uint32 `json:"hits"`
+	Misses     uint32 `json:"misses"`
+	Timeouts   uint32 `json:"timeouts"`
+	TotalConns uint32 `json:"total_conns"`
+	IdleConns  uint32 `json:"idle_conns"`
+	StaleConns uint32 `json:"stale_conns"`
+}
+
+func PostgresCheck(pool *pgxpool.Pool) Check {
+	return Check{
+		Name: "postgres",
+		Ping: func(ctx context.Context) error {
+			return pool.Ping(ctx)
+		},
+		Stats: func() interface{} {
+			stat := pool.Stat()
+			return PostgresPoolStats{
+				TotalConns:      stat.TotalConns(),
+				AcquiredConns:   stat.AcquiredConns(),
+				IdleConns:       stat.IdleConns(),
+				MaxConns:        stat.MaxConns(),
+				AcquireCount:    stat.AcquireCount(),
+				AcquireDuration: stat.AcquireDuration().String(),
+			}
+		},
+	}
+}
+
+func RedisCheck(client *redis.Client) Check {
+	return Check{
+		Name: "redis",
+		Ping: func(ctx context.Context) error {
+			return client.Ping(ctx).Err()
+		},
+		Stats: func() interface{} {
+			stat := client.PoolStats()
+			return RedisPoolStats{
+				Hits:       stat.Hits,
+				Misses:     stat.Misses,
+				Timeouts:   stat.Timeouts,
+				TotalConns: stat.TotalConns,
+				IdleConns:  stat.IdleConns,
+				StaleConns: stat.StaleConns,
+			}
+		},
+	}
+}
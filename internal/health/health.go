@@ -0,0 +1,138 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/felipeversiane/task-api/internal/log"
+)
+
+const defaultCheckTimeout = 2 * time.Second
+
+var shuttingDown atomic.Bool
+
+type Check struct {
+	Name    string
+	Timeout time.Duration
+	Ping    func(ctx context.Context) error
+	Stats   func() interface{}
+}
+
+type CheckResult struct {
+	Status  string      `json:"status"`
+	Latency string      `json:"latency"`
+	Error   string      `json:"error,omitempty"`
+	Stats   interface{} `json:"stats,omitempty"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+func MarkShuttingDown() {
+	shuttingDown.Store(true)
+}
+
+func IsShuttingDown() bool {
+	return shuttingDown.Load()
+}
+
+func Liveness(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "alive"})
+}
+
+// Readiness omits check errors and stats, which Details serves on the admin listener.
+func Readiness(checks ...Check) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := Run(r.Context(), checks...)
+		for name, result := range report.Checks {
+			if result.Error != "" {
+				log.FromContext(r.Context()).Warn("Readiness check failed",
+					slog.String("check", name),
+					slog.String("error", result.Error))
+			}
+			report.Checks[name] = CheckResult{Status: result.Status, Latency: result.Latency}
+		}
+		respondWithReport(w, report)
+	}
+}
+
+func Details(checks ...Check) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respondWithReport(w, Run(r.Context(), checks...))
+	}
+}
+
+func respondWithReport(w http.ResponseWriter, report Report) {
+	code := http.StatusOK
+	if report.Status != "ready" {
+		code = http.StatusServiceUnavailable
+	}
+	respondWithJSON(w, code, report)
+}
+
+func Run(ctx context.Context, checks ...Check) Report {
+	report := Report{Status: "ready", Checks: make(map[string]CheckResult, len(checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			result := runCheck(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = result
+			if result.Status != "up" {
+				report.Status = "not_ready"
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	if IsShuttingDown() {
+		report.Status = "shutting_down"
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, check Check) CheckResult {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = defaultCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Ping(ctx)
+	result := CheckResult{
+		Status:  "up",
+		Latency: time.Since(start).String(),
+	}
+	if err != nil {
+		result.Status = "down"
+		result.Error = err.Error()
+	}
+	if check.Stats != nil {
+		result.Stats = check.Stats()
+	}
+	return result
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	w.Write(response)
+}
@@ -0,0 +1,97 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func upCheck(name string) Check {
+	return Check{Name: name, Ping: func(ctx context.Context) error { return nil }}
+}
+
+func TestReadiness_ShouldReturnStatusOK_WhenEveryCheckIsUp(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Readiness(upCheck("postgres"), upCheck("redis"))(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 and received %d", rec.Code)
+	}
+
+	var report Report
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != "ready" || len(report.Checks) != 2 {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+}
+
+func TestReadiness_ShouldReturnStatusServiceUnavailable_WhenCheckTimesOut(t *testing.T) {
+	slow := Check{
+		Name:    "postgres",
+		Timeout: 10 * time.Millisecond,
+		Ping: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	failing := Check{
+		Name: "redis",
+		Ping: func(ctx context.Context) error { return errors.New("connection refused") },
+	}
+
+	report := Run(context.Background(), slow, failing)
+	if report.Status != "not_ready" {
+		t.Fatalf("Expected not_ready and received %q", report.Status)
+	}
+	if report.Checks["postgres"].Status != "down" || report.Checks["redis"].Error != "connection refused" {
+		t.Fatalf("Unexpected checks %+v", report.Checks)
+	}
+}
+
+func TestReadiness_ShouldReturnStatusServiceUnavailable_WhenShuttingDown(t *testing.T) {
+	MarkShuttingDown()
+	t.Cleanup(func() { shuttingDown.Store(false) })
+
+	rec := httptest.NewRecorder()
+	Readiness(upCheck("postgres"))(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503 and received %d", rec.Code)
+	}
+}
+
+func TestReadiness_ShouldOmitErrorsAndStats(t *testing.T) {
+	failing := Check{
+		Name:  "postgres",
+		Ping:  func(ctx context.Context) error { return errors.New("dial tcp db.internal:5432: connection refused") },
+		Stats: func() interface{} { return PostgresPoolStats{MaxConns: 10} },
+	}
+
+	rec := httptest.NewRecorder()
+	Readiness(failing)(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503 and received %d", rec.Code)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "db.internal") || strings.Contains(body, "stats") {
+		t.Fatalf("Expected the public report to omit errors and stats and received %s", body)
+	}
+
+	rec = httptest.NewRecorder()
+	Details(failing)(rec, httptest.NewRequest(http.MethodGet, "/debug/readyz", nil))
+
+	var report Report
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Checks["postgres"].Error == "" || report.Checks["postgres"].Stats == nil {
+		t.Fatalf("Expected the detailed report to include errors and stats and received %+v", report)
+	}
+}
@@ -0,0 +1,80 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/felipeversiane/task-api/internal/log"
+)
+
+type FallbackStore struct {
+	Primary   Store
+	Secondary Store
+}
+
+func NewFallbackStore(primary Store, secondary Store) *FallbackStore {
+	return &FallbackStore{
+		Primary:   primary,
+		Secondary: secondary,
+	}
+}
+
+func (s *FallbackStore) Acquire(ctx context.Context, key string, fingerprint string, lockTTL time.Duration) (*Record, bool, error) {
+	lock, acquired, err := s.Primary.Acquire(ctx, key, fingerprint, lockTTL)
+	if err != nil && err != ErrContended {
+		fallbackWarning(ctx, "acquire", err)
+		return s.Secondary.Acquire(ctx, key, fingerprint, lockTTL)
+	}
+	if !acquired {
+		return lock, false, err
+	}
+
+	existing, err := s.Secondary.Lookup(ctx, key)
+	if err != nil {
+		fallbackWarning(ctx, "lookup", err)
+		return lock, true, nil
+	}
+	if existing == nil {
+		return lock, true, nil
+	}
+
+	if err := s.Primary.Release(ctx, key, lock.Token); err != nil {
+		fallbackWarning(ctx, "release", err)
+	}
+	return existing, false, nil
+}
+
+func (s *FallbackStore) Lookup(ctx context.Context, key string) (*Record, error) {
+	record, err := s.Primary.Lookup(ctx, key)
+	if err != nil {
+		fallbackWarning(ctx, "lookup", err)
+	}
+	if record != nil {
+		return record, nil
+	}
+	return s.Secondary.Lookup(ctx, key)
+}
+
+func (s *FallbackStore) Complete(ctx context.Context, key string, token string, record Record, ttl time.Duration) error {
+	err := s.Primary.Complete(ctx, key, token, record, ttl)
+	if err == nil || errors.Is(err, ErrLockLost) {
+		return err
+	}
+	fallbackWarning(ctx, "complete", err)
+	return s.Secondary.Complete(ctx, key, token, record, ttl)
+}
+
+func (s *FallbackStore) Release(ctx context.Context, key string, token string) error {
+	if err := s.Primary.Release(ctx, key, token); err != nil {
+		fallbackWarning(ctx, "release", err)
+	}
+	return s.Secondary.Release(ctx, key, token)
+}
+
+func fallbackWarning(ctx context.Context, operation string, err error) {
+	log.FromContext(ctx).Warn("Idempotency store unavailable, falling back",
+		slog.String("operation", operation),
+		slog.Any("error", err))
+}
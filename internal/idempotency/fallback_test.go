@@ -0,0 +1,179 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errUnavailable = errors.New("store unavailable")
+
+type flakyStore struct {
+	*MemoryStore
+	down bool
+}
+
+func (s *flakyStore) Acquire(ctx context.Context, key string, fingerprint string, lockTTL time.Duration) (*Record, bool, error) {
+	if s.down {
+		return nil, false, errUnavailable
+	}
+	return s.MemoryStore.Acquire(ctx, key, fingerprint, lockTTL)
+}
+
+func (s *flakyStore) Lookup(ctx context.Context, key string) (*Record, error) {
+	if s.down {
+		return nil, errUnavailable
+	}
+	return s.MemoryStore.Lookup(ctx, key)
+}
+
+func (s *flakyStore) Complete(ctx context.Context, key string, token string, record Record, ttl time.Duration) error {
+	if s.down {
+		return errUnavailable
+	}
+	return s.MemoryStore.Complete(ctx, key, token, record, ttl)
+}
+
+func (s *flakyStore) Release(ctx context.Context, key string, token string) error {
+	if s.down {
+		return errUnavailable
+	}
+	return s.MemoryStore.Release(ctx, key, token)
+}
+
+func newFallbackStore() (*FallbackStore, *flakyStore, *MemoryStore) {
+	primary := &flakyStore{MemoryStore: NewMemoryStore()}
+	secondary := NewMemoryStore()
+	return NewFallbackStore(primary, secondary), primary, secondary
+}
+
+func TestFallbackStore_ShouldReplayResponseCompletedWhilePrimaryWasDown(t *testing.T) {
+	ctx := context.Background()
+	store, primary, _ := newFallbackStore()
+
+	primary.down = true
+	lock, acquired, err := store.Acquire(ctx, "key", "fingerprint", time.Minute)
+	if err != nil || !acquired {
+		t.Fatalf("Expected the secondary store to grant the lock and received %v, %v", acquired, err)
+	}
+	completed := Record{Fingerprint: "fingerprint", StatusCode: 201, Body: []byte(`{}`)}
+	if err := store.Complete(ctx, "key", lock.Token, completed, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Release(ctx, "key", lock.Token); err != nil {
+		t.Fatal(err)
+	}
+
+	primary.down = false
+	record, acquired, err := store.Acquire(ctx, "key", "fingerprint", time.Minute)
+	if err != nil || acquired {
+		t.Fatalf("Expected the completed record to be found and received %v, %v", acquired, err)
+	}
+	if record.StatusCode != completed.StatusCode {
+		t.Fatalf("Expected status %d to be replayed and received %d", completed.StatusCode, record.StatusCode)
+	}
+
+	if existing, _ := primary.Lookup(ctx, "key"); existing != nil {
+		t.Fatalf("Expected the primary lock to be released and received %+v", existing)
+	}
+}
+
+func TestFallbackStore_ShouldReportInFlightRequestFromSecondary(t *testing.T) {
+	ctx := context.Background()
+	store, primary, _ := newFallbackStore()
+
+	primary.down = true
+	if _, acquired, _ := store.Acquire(ctx, "key", "fingerprint", time.Minute); !acquired {
+		t.Fatal("Expected the secondary store to grant the lock")
+	}
+
+	primary.down = false
+	record, acquired, err := store.Acquire(ctx, "key", "fingerprint", time.Minute)
+	if err != nil || acquired || record.Completed() {
+		t.Fatalf("Expected the in-flight lock to be reported and received %+v, %v, %v", record, acquired, err)
+	}
+}
+
+func TestFallbackStore_ShouldUsePrimaryWhenSecondaryHasNoRecord(t *testing.T) {
+	ctx := context.Background()
+	store, primary, secondary := newFallbackStore()
+
+	lock, acquired, err := store.Acquire(ctx, "key", "fingerprint", time.Minute)
+	if err != nil || !acquired {
+		t.Fatalf("Expected the primary store to grant the lock and received %v, %v", acquired, err)
+	}
+	if existing, _ := secondary.Lookup(ctx, "key"); existing != nil {
+		t.Fatalf("Expected no lock in the secondary store and received %+v", existing)
+	}
+	if existing, _ := primary.Lookup(ctx, "key"); existing == nil || existing.Token != lock.Token {
+		t.Fatalf("Expected the primary lock to carry the returned token and received %+v", existing)
+	}
+}
+
+func TestMemoryStore_Release_ShouldKeepLockHeldByAnotherToken(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	stale, _, _ := store.Acquire(ctx, "key", "fingerprint", -time.Second)
+	current, acquired, _ := store.Acquire(ctx, "key", "fingerprint", time.Minute)
+	if !acquired {
+		t.Fatal("Expected the expired lock to be acquired again")
+	}
+
+	if err := store.Release(ctx, "key", stale.Token); err != nil {
+		t.Fatal(err)
+	}
+	if existing, _ := store.Lookup(ctx, "key"); existing == nil || existing.Token != current.Token {
+		t.Fatalf("Expected the current lock to survive a stale release and received %+v", existing)
+	}
+
+	if err := store.Release(ctx, "key", current.Token); err != nil {
+		t.Fatal(err)
+	}
+	if existing, _ := store.Lookup(ctx, "key"); existing != nil {
+		t.Fatalf("Expected the lock to be released and received %+v", existing)
+	}
+}
+
+func TestMemoryStore_Complete_ShouldRejectLockHeldByAnotherToken(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	stale, _, _ := store.Acquire(ctx, "key", "fingerprint", -time.Second)
+	current, _, _ := store.Acquire(ctx, "key", "fingerprint", time.Minute)
+
+	err := store.Complete(ctx, "key", stale.Token, Record{Fingerprint: "fingerprint", StatusCode: 201}, time.Hour)
+	if !errors.Is(err, ErrLockLost) {
+		t.Fatalf("Expected %v and received %v", ErrLockLost, err)
+	}
+	if existing, _ := store.Lookup(ctx, "key"); existing == nil || existing.Completed() || existing.Token != current.Token {
+		t.Fatalf("Expected the current lock to survive a stale completion and received %+v", existing)
+	}
+
+	if err := store.Complete(ctx, "key", current.Token, Record{Fingerprint: "fingerprint", StatusCode: 201}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	err = store.Complete(ctx, "key", current.Token, Record{Fingerprint: "fingerprint", StatusCode: 200}, time.Hour)
+	if !errors.Is(err, ErrLockLost) {
+		t.Fatalf("Expected a completed record to reject another completion and received %v", err)
+	}
+}
+
+func TestFallbackStore_Complete_ShouldNotFallBack_WhenLockIsLost(t *testing.T) {
+	ctx := context.Background()
+	store, primary, secondary := newFallbackStore()
+
+	stale, _, _ := primary.Acquire(ctx, "key", "fingerprint", -time.Second)
+	if _, acquired, _ := store.Acquire(ctx, "key", "fingerprint", time.Minute); !acquired {
+		t.Fatal("Expected the expired lock to be acquired again")
+	}
+
+	err := store.Complete(ctx, "key", stale.Token, Record{Fingerprint: "fingerprint", StatusCode: 201}, time.Hour)
+	if !errors.Is(err, ErrLockLost) {
+		t.Fatalf("Expected %v and received %v", ErrLockLost, err)
+	}
+	if existing, _ := secondary.Lookup(ctx, "key"); existing != nil {
+		t.Fatalf("Expected nothing to be written to the secondary store and received %+v", existing)
+	}
+}
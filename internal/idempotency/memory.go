@@ -0,0 +1,78 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryEntry struct {
+	record    Record
+	expiresAt time.Time
+}
+
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: map[string]memoryEntry{},
+	}
+}
+
+func (s *MemoryStore) Acquire(ctx context.Context, key string, fingerprint string, lockTTL time.Duration) (*Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && time.Now().Before(entry.expiresAt) {
+		record := entry.record
+		return &record, false, nil
+	}
+
+	lock := Record{Fingerprint: fingerprint, Token: uuid.NewString()}
+	s.entries[key] = memoryEntry{
+		record:    lock,
+		expiresAt: time.Now().Add(lockTTL),
+	}
+	return &lock, true, nil
+}
+
+func (s *MemoryStore) Lookup(ctx context.Context, key string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && time.Now().Before(entry.expiresAt) {
+		record := entry.record
+		return &record, nil
+	}
+	return nil, nil
+}
+
+func (s *MemoryStore) Complete(ctx context.Context, key string, token string, record Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && time.Now().Before(entry.expiresAt) && entry.record.Token != token {
+		return ErrLockLost
+	}
+	record.Token = ""
+	s.entries[key] = memoryEntry{
+		record:    record,
+		expiresAt: time.Now().Add(ttl),
+	}
+	return nil
+}
+
+func (s *MemoryStore) Release(ctx context.Context, key string, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && !entry.record.Completed() && entry.record.Token == token {
+		delete(s.entries, key)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const purgeInterval = time.Hour
+
+type PostgresStore struct {
+	Database *pgxpool.Pool
+}
+
+func NewPostgresStore(database *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{
+		Database: database,
+	}
+}
+
+func (s *PostgresStore) Acquire(ctx context.Context, key string, fingerprint string, lockTTL time.Duration) (*Record, bool, error) {
+	query := `INSERT INTO idempotency_keys (key, fingerprint, lock_token, expires_at)
+	          VALUES ($1, $2, $3, NOW() + $4 * INTERVAL '1 second')
+	          ON CONFLICT (key) DO UPDATE
+	          SET fingerprint = EXCLUDED.fingerprint, status_code = NULL, response_header = NULL,
+	              response_body = NULL, lock_token = EXCLUDED.lock_token, expires_at = EXCLUDED.expires_at
+	          WHERE idempotency_keys.expires_at < NOW()
+	          RETURNING key`
+
+	token := uuid.New()
+	var inserted string
+	err := s.Database.QueryRow(ctx, query, key, fingerprint, token, lockTTL.Seconds()).Scan(&inserted)
+	if err == nil {
+		return &Record{Fingerprint: fingerprint, Token: token.String()}, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, err
+	}
+
+	record, err := s.Lookup(ctx, key)
+	if err != nil {
+		return nil, false, err
+	}
+	if record == nil {
+		return nil, false, ErrContended
+	}
+	return record, false, nil
+}
+
+func (s *PostgresStore) Lookup(ctx context.Context, key string) (*Record, error) {
+	query := `SELECT fingerprint, COALESCE(status_code, 0), response_header, response_body, COALESCE(lock_token::text, '')
+	          FROM idempotency_keys WHERE key = $1 AND expires_at >= NOW()`
+
+	var record Record
+	var header []byte
+	err := s.Database.QueryRow(ctx, query, key).Scan(&record.Fingerprint, &record.StatusCode, &header, &record.Body, &record.Token)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(header) > 0 {
+		if err := json.Unmarshal(header, &record.Header); err != nil {
+			return nil, err
+		}
+	}
+	return &record, nil
+}
+
+func (s *PostgresStore) Complete(ctx context.Context, key string, token string, record Record, ttl time.Duration) error {
+	header, err := json.Marshal(record.Header)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO idempotency_keys (key, fingerprint, status_code, response_header, response_body, expires_at)
+	          VALUES ($1, $2, $3, $4, $5, NOW() + $6 * INTERVAL '1 second')
+	          ON CONFLICT (key) DO UPDATE
+	          SET fingerprint = EXCLUDED.fingerprint, status_code = EXCLUDED.status_code,
+	              response_header = EXCLUDED.response_header, response_body = EXCLUDED.response_body,
+	              lock_token = NULL, expires_at = EXCLUDED.expires_at
+	          WHERE idempotency_keys.lock_token::text = $7 OR idempotency_keys.expires_at < NOW()
+	          RETURNING key`
+
+	var stored string
+	err = s.Database.QueryRow(ctx, query, key, record.Fingerprint, record.StatusCode, header, record.Body, ttl.Seconds(), token).Scan(&stored)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrLockLost
+	}
+	return err
+}
+
+func (s *PostgresStore) Release(ctx context.Context, key string, token string) error {
+	lockToken, err := uuid.Parse(token)
+	if err != nil {
+		return nil
+	}
+	_, err = s.Database.Exec(ctx, `DELETE FROM idempotency_keys
+	                               WHERE key = $1 AND status_code IS NULL AND lock_token = $2`, key, lockToken)
+	return err
+}
+
+func (s *PostgresStore) Purge(ctx context.Context) (int64, error) {
+	tag, err := s.Database.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (s *PostgresStore) Run(ctx context.Context) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := s.Purge(ctx); err != nil {
+			if ctx.Err() == nil {
+				slog.Error("Failed to purge expired idempotency keys", slog.Any("error", err))
+			}
+		} else if purged > 0 {
+			slog.Info("Purged expired idempotency keys", slog.Int64("count", purged))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var releaseScript = redis.NewScript(`
+local value = redis.call('GET', KEYS[1])
+if value and cjson.decode(value).token == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+var completeScript = redis.NewScript(`
+local value = redis.call('GET', KEYS[1])
+if value and cjson.decode(value).token ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 1
+`)
+
+type RedisStore struct {
+	Client *redis.Client
+}
+
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{
+		Client: client,
+	}
+}
+
+func (s *RedisStore) Acquire(ctx context.Context, key string, fingerprint string, lockTTL time.Duration) (*Record, bool, error) {
+	lock := Record{Fingerprint: fingerprint, Token: uuid.NewString()}
+	value, err := json.Marshal(lock)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		acquired, err := s.Client.SetNX(ctx, redisKey(key), value, lockTTL).Result()
+		if err != nil {
+			return nil, false, err
+		}
+		if acquired {
+			return &lock, true, nil
+		}
+
+		record, err := s.Lookup(ctx, key)
+		if err != nil {
+			return nil, false, err
+		}
+		if record != nil {
+			return record, false, nil
+		}
+	}
+	return nil, false, ErrContended
+}
+
+func (s *RedisStore) Lookup(ctx context.Context, key string) (*Record, error) {
+	value, err := s.Client.Get(ctx, redisKey(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var record Record
+	if err := json.Unmarshal(value, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *RedisStore) Complete(ctx context.Context, key string, token string, record Record, ttl time.Duration) error {
+	record.Token = ""
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	stored, err := completeScript.Run(ctx, s.Client, []string{redisKey(key)}, token, value, ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if stored == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+func (s *RedisStore) Release(ctx context.Context, key string, token string) error {
+	return releaseScript.Run(ctx, s.Client, []string{redisKey(key)}, token).Err()
+}
+
+func redisKey(key string) string {
+	return fmt.Sprintf("idempotency:%s", key)
+}
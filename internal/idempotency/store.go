@@ -0,0 +1,38 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrContended = errors.New("idempotency key is contended")
+	ErrLockLost  = errors.New("idempotency lock is held by another request")
+)
+
+type Record struct {
+	Fingerprint string            `json:"fingerprint"`
+	StatusCode  int               `json:"status_code,omitempty"`
+	Header      map[string]string `json:"header,omitempty"`
+	Body        []byte            `json:"body,omitempty"`
+	Token       string            `json:"token,omitempty"`
+}
+
+func (r *Record) Completed() bool {
+	return r.StatusCode != 0
+}
+
+type Store interface {
+	Acquire(ctx context.Context, key string, fingerprint string, lockTTL time.Duration) (*Record, bool, error)
+	Lookup(ctx context.Context, key string) (*Record, error)
+	Complete(ctx context.Context, key string, token string, record Record, ttl time.Duration) error
+	Release(ctx context.Context, key string, token string) error
+}
+
+var (
+	_ Store = (*RedisStore)(nil)
+	_ Store = (*PostgresStore)(nil)
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*FallbackStore)(nil)
+)
@@ -3,21 +3,19 @@ package log
 import (
 	"log/slog"
 	"os"
+	"strings"
 )
 
-func Configure() {
-	level := getLogLevel()
-	logConfig := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level}))
+func Configure(level string) {
+	logConfig := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: getLogLevel(level)}))
 
 	slog.SetDefault(logConfig)
 
 	slog.Debug("Log configured")
 }
 
-func getLogLevel() slog.Level {
-	level := os.Getenv("LOG_LEVEL")
-
-	switch level {
+func getLogLevel(level string) slog.Level {
+	switch strings.ToUpper(level) {
 	case "DEBUG":
 		return slog.LevelDebug
 	case "INFO":
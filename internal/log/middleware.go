@@ -2,7 +2,6 @@ package log
 
 import (
 	"net/http"
-	"os"
 	"time"
 
 	"log/slog"
@@ -12,17 +11,12 @@ func LogMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
-		handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-			Level: slog.LevelInfo,
-		})
-		logger := slog.New(handler)
-
 		lrw := &loggingResponseWriter{w, http.StatusOK}
 
 		next.ServeHTTP(lrw, r)
 
 		latency := time.Since(start)
-		logger.Info(
+		FromContext(r.Context()).Info(
 			"request_details",
 			slog.String("method", r.Method),
 			slog.Int("status", lrw.status),
@@ -47,3 +41,7 @@ func (lrw *loggingResponseWriter) WriteHeader(statusCode int) {
 func (lrw *loggingResponseWriter) Write(b []byte) (int, error) {
 	return lrw.ResponseWriter.Write(b)
 }
+
+func (lrw *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return lrw.ResponseWriter
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	httpRequests = promauto.With(Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests by route pattern, method and status class.",
+	}, []string{"method", "route", "status_class"})
+	httpDuration = promauto.With(Default).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by route pattern, method and status class.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status_class"})
+)
+
+func Middleware(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		_, route := mux.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+
+		mrw := &metricsResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		mux.ServeHTTP(mrw, r)
+
+		class := statusClass(mrw.status)
+		httpRequests.WithLabelValues(r.Method, route, class).Inc()
+		httpDuration.WithLabelValues(r.Method, route, class).Observe(time.Since(start).Seconds())
+	})
+}
+
+type metricsResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (mrw *metricsResponseWriter) WriteHeader(statusCode int) {
+	if !mrw.wroteHeader {
+		mrw.status = statusCode
+		mrw.wroteHeader = true
+	}
+	mrw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (mrw *metricsResponseWriter) Unwrap() http.ResponseWriter {
+	return mrw.ResponseWriter
+}
+
+func statusClass(code int) string {
+	return fmt.Sprintf("%dxx", code/100)
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMiddleware_ShouldLabelByRoutePattern(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/tasks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	handler := Middleware(mux)
+
+	requests := httpRequests.WithLabelValues(http.MethodGet, "GET /api/v1/tasks/{id}", "4xx")
+	before := testutil.ToFloat64(requests)
+	for _, id := range []string{"a", "b"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/tasks/"+id, nil))
+	}
+
+	if got := testutil.ToFloat64(requests) - before; got != 2 {
+		t.Fatalf("Expected 2 requests on the route pattern and received %v", got)
+	}
+}
+
+func TestHandler_ShouldExposeRegisteredMetrics(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/tasks", func(w http.ResponseWriter, r *http.Request) {})
+	Middleware(mux).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/tasks", nil))
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	out := recorder.Body.String()
+
+	for _, expected := range []string{
+		"# TYPE http_requests_total counter",
+		`http_requests_total{method="GET",route="GET /api/v1/tasks",status_class="2xx"} 1`,
+		"# TYPE http_request_duration_seconds histogram",
+		`http_request_duration_seconds_count{method="GET",route="GET /api/v1/tasks",status_class="2xx"} 1`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Expected %q in output:\n%s", expected, out)
+		}
+	}
+}
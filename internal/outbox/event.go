@@ -0,0 +1,60 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	TaskCreated = "task.created"
+	TaskUpdated = "task.updated"
+	TaskDeleted = "task.deleted"
+)
+
+type Event struct {
+	ID          uuid.UUID
+	AggregateID uuid.UUID
+	Type        string
+	Payload     json.RawMessage
+	CreatedAt   time.Time
+}
+
+func NewEvent(eventType string, aggregateID uuid.UUID, payload interface{}) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		ID:          uuid.New(),
+		AggregateID: aggregateID,
+		Type:        eventType,
+		Payload:     data,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+func Write(ctx context.Context, tx pgx.Tx, events ...Event) error {
+	switch len(events) {
+	case 0:
+		return nil
+	case 1:
+		event := events[0]
+		_, err := tx.Exec(ctx, `INSERT INTO task_outbox (event_id, aggregate_id, event_type, payload, created_at)
+		                        VALUES ($1, $2, $3, $4, $5)`,
+			event.ID, event.AggregateID, event.Type, event.Payload, event.CreatedAt)
+		return err
+	}
+
+	rows := make([][]interface{}, len(events))
+	for i, event := range events {
+		rows[i] = []interface{}{event.ID, event.AggregateID, event.Type, event.Payload, event.CreatedAt}
+	}
+	columns := []string{"event_id", "aggregate_id", "event_type", "payload", "created_at"}
+	_, err := tx.CopyFrom(ctx, pgx.Identifier{"task_outbox"}, columns, pgx.CopyFromRows(rows))
+	return err
+}
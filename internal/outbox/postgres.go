@@ -0,0 +1,84 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresStore struct {
+	Database *pgxpool.Pool
+}
+
+type pendingEvent struct {
+	Event
+	sequence int64
+}
+
+func NewPostgresStore(database *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{Database: database}
+}
+
+func (s *PostgresStore) Relay(ctx context.Context, limit int, publish PublishFunc) ([]Event, int, error) {
+	tx, err := s.Database.Begin(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `SELECT id, event_id, aggregate_id, event_type, payload, created_at
+	                            FROM task_outbox
+	                            WHERE published_at IS NULL
+	                            ORDER BY id
+	                            LIMIT $1
+	                            FOR UPDATE SKIP LOCKED`, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	pending, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (pendingEvent, error) {
+		var event pendingEvent
+		err := row.Scan(&event.sequence, &event.ID, &event.AggregateID, &event.Type, &event.Payload, &event.CreatedAt)
+		return event, err
+	})
+	if err != nil || len(pending) == 0 {
+		return nil, 0, err
+	}
+
+	events := make([]Event, len(pending))
+	for i, event := range pending {
+		events[i] = event.Event
+	}
+	published, publishErr := publish(ctx, events)
+
+	if published > 0 {
+		sequences := make([]int64, published)
+		for i, event := range pending[:published] {
+			sequences[i] = event.sequence
+		}
+		if _, err := tx.Exec(ctx, `UPDATE task_outbox SET published_at = NOW(), attempts = attempts + 1
+		                           WHERE id = ANY($1)`, sequences); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if publishErr != nil && published < len(pending) {
+		failed := pending[published]
+		if _, err := tx.Exec(ctx, `UPDATE task_outbox SET attempts = attempts + 1, last_error = $2
+		                           WHERE id = $1`, failed.sequence, publishErr.Error()); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, 0, err
+	}
+	return events, published, publishErr
+}
+
+func (s *PostgresStore) Purge(ctx context.Context, retention time.Duration) error {
+	_, err := s.Database.Exec(ctx, `DELETE FROM task_outbox
+	                                WHERE published_at < NOW() - $1 * INTERVAL '1 second'`, retention.Seconds())
+	return err
+}
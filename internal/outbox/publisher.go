@@ -0,0 +1,58 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/felipeversiane/task-api/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// Publisher reports how many events it accepted, in order, before the first failure.
+type Publisher interface {
+	Publish(ctx context.Context, events []Event) (int, error)
+}
+
+type StreamPublisher struct {
+	Client *redis.Client
+	Stream string
+	MaxLen int64
+}
+
+func NewStreamPublisher(client *redis.Client, cfg config.OutboxConfig) *StreamPublisher {
+	return &StreamPublisher{
+		Client: client,
+		Stream: cfg.Stream,
+		MaxLen: int64(cfg.MaxLen),
+	}
+}
+
+func (p *StreamPublisher) Publish(ctx context.Context, events []Event) (int, error) {
+	cmds, err := p.Client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, event := range events {
+			pipe.XAdd(ctx, &redis.XAddArgs{
+				Stream: p.Stream,
+				MaxLen: p.MaxLen,
+				Approx: true,
+				Values: map[string]interface{}{
+					"event_id":     event.ID.String(),
+					"type":         event.Type,
+					"aggregate_id": event.AggregateID.String(),
+					"payload":      string(event.Payload),
+					"created_at":   event.CreatedAt.Format(time.RFC3339Nano),
+				},
+			})
+		}
+		return nil
+	})
+
+	for i, cmd := range cmds {
+		if cmd.Err() != nil {
+			return i, cmd.Err()
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(events), nil
+}
@@ -0,0 +1,114 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/felipeversiane/task-api/internal/config"
+	"github.com/felipeversiane/task-api/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const purgeInterval = time.Hour
+
+var (
+	publishedEvents = promauto.With(metrics.Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_events_published_total",
+		Help: "Outbox events handed to every publisher.",
+	}, []string{"type"})
+	failedEvents = promauto.With(metrics.Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_publish_failures_total",
+		Help: "Outbox events that failed to publish and will be retried.",
+	}, []string{"type"})
+)
+
+type PublishFunc func(ctx context.Context, events []Event) (int, error)
+
+// Store marks the events accepted by publish as published and records the error of the first rejected one.
+type Store interface {
+	Relay(ctx context.Context, limit int, publish PublishFunc) ([]Event, int, error)
+	Purge(ctx context.Context, retention time.Duration) error
+}
+
+type Relay struct {
+	Store        Store
+	Publishers   []Publisher
+	PollInterval time.Duration
+	BatchSize    int
+	Retention    time.Duration
+	lastPurge    time.Time
+}
+
+func NewRelay(store Store, cfg config.OutboxConfig, publishers ...Publisher) *Relay {
+	return &Relay{
+		Store:        store,
+		Publishers:   publishers,
+		PollInterval: cfg.PollInterval,
+		BatchSize:    cfg.BatchSize,
+		Retention:    cfg.Retention,
+	}
+}
+
+func (r *Relay) Run(ctx context.Context) {
+	slog.Info("Outbox relay started", slog.Int("publishers", len(r.Publishers)))
+
+	ticker := time.NewTicker(r.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			published, err := r.publishBatch(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.Error("Failed to relay outbox events", slog.Any("error", err))
+				}
+				break
+			}
+			if published < r.BatchSize {
+				break
+			}
+		}
+		r.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			slog.Info("Outbox relay stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Relay) publishBatch(ctx context.Context) (int, error) {
+	events, published, err := r.Store.Relay(ctx, r.BatchSize, r.publish)
+	for _, event := range events[:published] {
+		publishedEvents.WithLabelValues(event.Type).Inc()
+	}
+	if err != nil && published < len(events) {
+		failedEvents.WithLabelValues(events[published].Type).Inc()
+	}
+	return published, err
+}
+
+// publish stops at the first failing publisher so unaccepted events are retried for all of them.
+func (r *Relay) publish(ctx context.Context, events []Event) (int, error) {
+	for _, publisher := range r.Publishers {
+		if published, err := publisher.Publish(ctx, events); err != nil {
+			return published, err
+		}
+	}
+	return len(events), nil
+}
+
+func (r *Relay) purge(ctx context.Context) {
+	if time.Since(r.lastPurge) < purgeInterval {
+		return
+	}
+	r.lastPurge = time.Now()
+
+	if err := r.Store.Purge(ctx, r.Retention); err != nil && ctx.Err() == nil {
+		slog.Error("Failed to purge published outbox events", slog.Any("error", err))
+	}
+}
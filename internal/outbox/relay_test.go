@@ -0,0 +1,154 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type storedEvent struct {
+	Event
+	published bool
+	attempts  int
+	lastError string
+}
+
+type fakeStore struct {
+	mu     sync.Mutex
+	events []*storedEvent
+}
+
+func (s *fakeStore) add(types ...string) {
+	for _, eventType := range types {
+		event, _ := NewEvent(eventType, uuid.New(), map[string]string{})
+		s.events = append(s.events, &storedEvent{Event: event})
+	}
+}
+
+func (s *fakeStore) Relay(ctx context.Context, limit int, publish PublishFunc) ([]Event, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pending []*storedEvent
+	for _, event := range s.events {
+		if !event.published && len(pending) < limit {
+			pending = append(pending, event)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, 0, nil
+	}
+
+	events := make([]Event, len(pending))
+	for i, event := range pending {
+		events[i] = event.Event
+	}
+	published, err := publish(ctx, events)
+	for _, event := range pending[:published] {
+		event.published = true
+		event.attempts++
+	}
+	if err != nil && published < len(pending) {
+		pending[published].attempts++
+		pending[published].lastError = err.Error()
+	}
+	return events, published, err
+}
+
+func (s *fakeStore) Purge(ctx context.Context, retention time.Duration) error {
+	return nil
+}
+
+type fakePublisher struct {
+	received []string
+	failAt   int
+	err      error
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, events []Event) (int, error) {
+	for i, event := range events {
+		if p.err != nil && len(p.received) == p.failAt {
+			err := p.err
+			p.err = nil
+			return i, err
+		}
+		p.received = append(p.received, event.Type)
+	}
+	return len(events), nil
+}
+
+func newTestRelay(store Store, publishers ...Publisher) *Relay {
+	return &Relay{Store: store, Publishers: publishers, BatchSize: 10}
+}
+
+func TestRelay_ShouldPublishPendingEventsInOrder(t *testing.T) {
+	store := &fakeStore{}
+	store.add(TaskCreated, TaskUpdated, TaskDeleted)
+	publisher := &fakePublisher{}
+
+	published, err := newTestRelay(store, publisher).publishBatch(context.Background())
+	if err != nil || published != 3 {
+		t.Fatalf("Expected 3 events to be published and received %d, %v", published, err)
+	}
+	if expected := []string{TaskCreated, TaskUpdated, TaskDeleted}; !reflect.DeepEqual(publisher.received, expected) {
+		t.Fatalf("Expected events %v in outbox order and received %v", expected, publisher.received)
+	}
+	for _, event := range store.events {
+		if !event.published || event.attempts != 1 {
+			t.Fatalf("Expected every event to be marked published and received %+v", event)
+		}
+	}
+
+	if published, err := newTestRelay(store, publisher).publishBatch(context.Background()); err != nil || published != 0 {
+		t.Fatalf("Expected nothing left to publish and received %d, %v", published, err)
+	}
+}
+
+func TestRelay_ShouldRetryFromFirstFailedEvent(t *testing.T) {
+	store := &fakeStore{}
+	store.add(TaskCreated, TaskUpdated, TaskDeleted)
+	publisher := &fakePublisher{failAt: 1, err: errors.New("stream unavailable")}
+	relay := newTestRelay(store, publisher)
+
+	published, err := relay.publishBatch(context.Background())
+	if err == nil || published != 1 {
+		t.Fatalf("Expected the batch to stop at the failed event and received %d, %v", published, err)
+	}
+	if failed := store.events[1]; failed.published || failed.attempts != 1 || failed.lastError != "stream unavailable" {
+		t.Fatalf("Expected the failed event to stay pending with its error and received %+v", failed)
+	}
+	if store.events[2].published || store.events[2].attempts != 0 {
+		t.Fatalf("Expected events after the failure to stay untouched and received %+v", store.events[2])
+	}
+
+	published, err = relay.publishBatch(context.Background())
+	if err != nil || published != 2 {
+		t.Fatalf("Expected the remaining events to be retried and received %d, %v", published, err)
+	}
+	if expected := []string{TaskCreated, TaskUpdated, TaskDeleted}; !reflect.DeepEqual(publisher.received, expected) {
+		t.Fatalf("Expected events %v in outbox order and received %v", expected, publisher.received)
+	}
+}
+
+func TestRelay_ShouldNotMarkEventsRejectedByLaterPublisher(t *testing.T) {
+	store := &fakeStore{}
+	store.add(TaskCreated, TaskUpdated)
+	first := &fakePublisher{}
+	second := &fakePublisher{failAt: 0, err: errors.New("webhooks unavailable")}
+	relay := newTestRelay(store, first, second)
+
+	if published, err := relay.publishBatch(context.Background()); err == nil || published != 0 {
+		t.Fatalf("Expected no events to be marked published and received %d, %v", published, err)
+	}
+	if published, err := relay.publishBatch(context.Background()); err != nil || published != 2 {
+		t.Fatalf("Expected both events to be retried and received %d, %v", published, err)
+	}
+	if expected := []string{TaskCreated, TaskUpdated}; !reflect.DeepEqual(second.received, expected) {
+		t.Fatalf("Expected the failing publisher to receive %v and received %v", expected, second.received)
+	}
+}
@@ -0,0 +1,94 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func assertJSON(t *testing.T, got []byte, expected string) {
+	t.Helper()
+	var gotValue, expectedValue interface{}
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(expected), &expectedValue); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotValue, expectedValue) {
+		t.Fatalf("Expected %s and received %s", expected, got)
+	}
+}
+
+// Examples from RFC 6902, Appendix A.
+func TestJSONPatch(t *testing.T) {
+	cases := []struct {
+		name     string
+		doc      string
+		patch    string
+		expected string
+	}{
+		{"add object member", `{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux"}]`, `{"baz": "qux", "foo": "bar"}`},
+		{"add array element", `{"foo": ["bar", "baz"]}`, `[{"op": "add", "path": "/foo/1", "value": "qux"}]`, `{"foo": ["bar", "qux", "baz"]}`},
+		{"remove object member", `{"baz": "qux", "foo": "bar"}`, `[{"op": "remove", "path": "/baz"}]`, `{"foo": "bar"}`},
+		{"remove array element", `{"foo": ["bar", "qux", "baz"]}`, `[{"op": "remove", "path": "/foo/1"}]`, `{"foo": ["bar", "baz"]}`},
+		{"replace value", `{"baz": "qux", "foo": "bar"}`, `[{"op": "replace", "path": "/baz", "value": "boo"}]`, `{"baz": "boo", "foo": "bar"}`},
+		{
+			"move value",
+			`{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			`[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			`{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+		},
+		{"move array element", `{"foo": ["all", "grass", "cows", "eat"]}`, `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`, `{"foo": ["all", "cows", "eat", "grass"]}`},
+		{
+			"test value",
+			`{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			`[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2}]`,
+			`{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		},
+		{"add nested member object", `{"foo": "bar"}`, `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`, `{"foo": "bar", "child": {"grandchild": {}}}`},
+		{"ignore unrecognized elements", `{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`, `{"foo": "bar", "baz": "qux"}`},
+		{"escape ordering", `{"/": 9, "~1": 10}`, `[{"op": "test", "path": "/~01", "value": 10}]`, `{"/": 9, "~1": 10}`},
+		{"add array value", `{"foo": ["bar"]}`, `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`, `{"foo": ["bar", ["abc", "def"]]}`},
+		{"add escaped member", `{}`, `[{"op": "add", "path": "/a~1b", "value": 1}, {"op": "add", "path": "/m~0n", "value": 2}]`, `{"a/b": 1, "m~n": 2}`},
+		{"copy value", `{"foo": {"bar": [1]}}`, `[{"op": "copy", "from": "/foo", "path": "/baz"}, {"op": "add", "path": "/baz/bar/-", "value": 2}]`, `{"foo": {"bar": [1]}, "baz": {"bar": [1, 2]}}`},
+		{"replace whole document", `{"foo": "bar"}`, `[{"op": "add", "path": "", "value": ["baz"]}]`, `["baz"]`},
+	}
+	for _, c := range cases {
+		got, err := JSONPatch([]byte(c.doc), []byte(c.patch))
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		assertJSON(t, got, c.expected)
+	}
+}
+
+func TestJSONPatch_ShouldReturnError(t *testing.T) {
+	cases := []struct {
+		name     string
+		doc      string
+		patch    string
+		expected error
+	}{
+		{"test value mismatch", `{"baz": "qux", "foo": ["a", 2, "c"]}`, `[{"op": "test", "path": "/baz", "value": "bar"}]`, ErrTestFailed},
+		{"compare strings and numbers", `{"/": 9, "~1": 10}`, `[{"op": "test", "path": "/~01", "value": "10"}]`, ErrTestFailed},
+		{"add to nonexistent target", `{"foo": "bar"}`, `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`, ErrInvalidPath},
+		{"remove missing member", `{"foo": "bar"}`, `[{"op": "remove", "path": "/baz"}]`, ErrInvalidPath},
+		{"index past the end", `{"foo": ["bar"]}`, `[{"op": "add", "path": "/foo/2", "value": "qux"}]`, ErrInvalidPath},
+		{"index with leading zero", `{"foo": ["bar", "baz"]}`, `[{"op": "remove", "path": "/foo/01"}]`, ErrInvalidPath},
+		{"remove end of array", `{"foo": ["bar"]}`, `[{"op": "remove", "path": "/foo/-"}]`, ErrInvalidPath},
+		{"pointer without slash", `{"foo": "bar"}`, `[{"op": "remove", "path": "foo"}]`, ErrInvalidPath},
+		{"move into own child", `{"foo": {"bar": 1}}`, `[{"op": "move", "from": "/foo", "path": "/foo/bar/baz"}]`, ErrInvalidPath},
+		{"missing value", `{"foo": "bar"}`, `[{"op": "add", "path": "/baz"}]`, ErrInvalidPatch},
+		{"unknown operation", `{"foo": "bar"}`, `[{"op": "merge", "path": "/baz", "value": 1}]`, ErrInvalidPatch},
+		{"patch is not an array", `{"foo": "bar"}`, `{"op": "add", "path": "/baz", "value": 1}`, ErrInvalidPatch},
+		{"invalid document", `{`, `[]`, ErrInvalidDocument},
+	}
+	for _, c := range cases {
+		_, err := JSONPatch([]byte(c.doc), []byte(c.patch))
+		if !errors.Is(err, c.expected) {
+			t.Fatalf("%s: expected %v and received %v", c.name, c.expected, err)
+		}
+	}
+}
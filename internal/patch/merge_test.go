@@ -0,0 +1,47 @@
+package patch
+
+import (
+	"errors"
+	"testing"
+)
+
+// Examples from RFC 7396, Appendix A.
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		doc      string
+		patch    string
+		expected string
+	}{
+		{`{"a": "b"}`, `{"a": "c"}`, `{"a": "c"}`},
+		{`{"a": "b"}`, `{"b": "c"}`, `{"a": "b", "b": "c"}`},
+		{`{"a": "b"}`, `{"a": null}`, `{}`},
+		{`{"a": "b", "b": "c"}`, `{"a": null}`, `{"b": "c"}`},
+		{`{"a": ["b"]}`, `{"a": "c"}`, `{"a": "c"}`},
+		{`{"a": "c"}`, `{"a": ["b"]}`, `{"a": ["b"]}`},
+		{`{"a": {"b": "c"}}`, `{"a": {"b": "d", "c": null}}`, `{"a": {"b": "d"}}`},
+		{`{"a": [{"b": "c"}]}`, `{"a": [1]}`, `{"a": [1]}`},
+		{`["a", "b"]`, `["c", "d"]`, `["c", "d"]`},
+		{`{"a": "b"}`, `["c"]`, `["c"]`},
+		{`{"a": "foo"}`, `null`, `null`},
+		{`{"a": "foo"}`, `"bar"`, `"bar"`},
+		{`{"e": null}`, `{"a": 1}`, `{"e": null, "a": 1}`},
+		{`[1, 2]`, `{"a": "b", "c": null}`, `{"a": "b"}`},
+		{`{}`, `{"a": {"bb": {"ccc": null}}}`, `{"a": {"bb": {}}}`},
+	}
+	for _, c := range cases {
+		got, err := MergePatch([]byte(c.doc), []byte(c.patch))
+		if err != nil {
+			t.Fatalf("MergePatch(%s, %s) returned %v", c.doc, c.patch, err)
+		}
+		assertJSON(t, got, c.expected)
+	}
+}
+
+func TestMergePatch_ShouldReturnError_WhenInputIsInvalid(t *testing.T) {
+	if _, err := MergePatch([]byte(`{`), []byte(`{}`)); !errors.Is(err, ErrInvalidDocument) {
+		t.Fatalf("Expected %v and received %v", ErrInvalidDocument, err)
+	}
+	if _, err := MergePatch([]byte(`{}`), []byte(`{`)); !errors.Is(err, ErrInvalidPatch) {
+		t.Fatalf("Expected %v and received %v", ErrInvalidPatch, err)
+	}
+}
@@ -5,9 +5,17 @@ import (
 )
 
 type RestError struct {
+	Message   string  `json:"message"`
+	Err       string  `json:"error"`
+	Code      int     `json:"code"`
+	RequestID string  `json:"request_id,omitempty"`
+	Causes    []Cause `json:"causes,omitempty"`
+}
+
+type Cause struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
 	Message string `json:"message"`
-	Err     string `json:"error"`
-	Code    int    `json:"code"`
 }
 
 func (r *RestError) Error() string {
@@ -22,6 +30,15 @@ func NewBadRequestError(message string) *RestError {
 	}
 }
 
+func NewValidationError(message string, causes []Cause) *RestError {
+	return &RestError{
+		Message: message,
+		Err:     "bad_request",
+		Code:    http.StatusBadRequest,
+		Causes:  causes,
+	}
+}
+
 func NewUnauthorizedRequestError(message string) *RestError {
 	return &RestError{
 		Message: message,
@@ -53,3 +70,51 @@ func NewForbiddenError(message string) *RestError {
 		Code:    http.StatusForbidden,
 	}
 }
+
+func NewConflictError(message string) *RestError {
+	return &RestError{
+		Message: message,
+		Err:     "conflict",
+		Code:    http.StatusConflict,
+	}
+}
+
+func NewUnsupportedMediaTypeError(message string) *RestError {
+	return &RestError{
+		Message: message,
+		Err:     "unsupported_media_type",
+		Code:    http.StatusUnsupportedMediaType,
+	}
+}
+
+func NewPreconditionFailedError(message string) *RestError {
+	return &RestError{
+		Message: message,
+		Err:     "precondition_failed",
+		Code:    http.StatusPreconditionFailed,
+	}
+}
+
+func NewUnprocessableEntityError(message string) *RestError {
+	return &RestError{
+		Message: message,
+		Err:     "unprocessable_entity",
+		Code:    http.StatusUnprocessableEntity,
+	}
+}
+
+func NewTooManyRequestsError(message string) *RestError {
+	return &RestError{
+		Message: message,
+		Err:     "too_many_requests",
+		Code:    http.StatusTooManyRequests,
+	}
+}
+
+func NewServiceUnavailableError(message string) *RestError {
+	return &RestError{
+		Message: message,
+		Err:     "service_unavailable",
+		Code:    http.StatusServiceUnavailable,
+	}
+}
@@ -0,0 +1,86 @@
+package rest
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	ProblemContentType = "application/problem+json"
+	ProblemTypeBaseURI = "https://github.com/felipeversiane/task-api/blob/main/docs/problems.md#"
+)
+
+type problemType struct {
+	slug  string
+	title string
+}
+
+var problemTypes = map[string]problemType{
+	"bad_request":            {slug: "bad-request", title: "Bad Request"},
+	"unauthorized":           {slug: "unauthorized", title: "Unauthorized"},
+	"forbidden":              {slug: "forbidden", title: "Forbidden"},
+	"not_found":              {slug: "not-found", title: "Not Found"},
+	"conflict":               {slug: "conflict", title: "Conflict"},
+	"precondition_failed":    {slug: "precondition-failed", title: "Precondition Failed"},
+	"unsupported_media_type": {slug: "unsupported-media-type", title: "Unsupported Media Type"},
+	"unprocessable_entity":   {slug: "unprocessable-entity", title: "Unprocessable Entity"},
+	"too_many_requests":      {slug: "too-many-requests", title: "Too Many Requests"},
+	"internal_server_error":  {slug: "internal-server-error", title: "Internal Server Error"},
+	"service_unavailable":    {slug: "service-unavailable", title: "Service Unavailable"},
+}
+
+type Problem struct {
+	Type      string  `json:"type"`
+	Title     string  `json:"title"`
+	Status    int     `json:"status"`
+	Detail    string  `json:"detail,omitempty"`
+	Instance  string  `json:"instance,omitempty"`
+	Err       string  `json:"error,omitempty"`
+	RequestID string  `json:"request_id,omitempty"`
+	Causes    []Cause `json:"causes,omitempty"`
+}
+
+func (r *RestError) Problem(instance string) Problem {
+	problem := Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(r.Code),
+		Status:    r.Code,
+		Detail:    r.Message,
+		Instance:  instance,
+		Err:       r.Err,
+		RequestID: r.RequestID,
+		Causes:    r.Causes,
+	}
+	if pt, ok := problemTypes[r.Err]; ok {
+		problem.Type = ProblemTypeBaseURI + pt.slug
+		problem.Title = pt.title
+	}
+	return problem
+}
+
+func PrefersProblem(accept string) bool {
+	problemQ, jsonQ := 0.0, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		switch mediaType {
+		case ProblemContentType:
+			problemQ = max(problemQ, q)
+		case "application/json":
+			jsonQ = max(jsonQ, q)
+		}
+	}
+	return problemQ > 0 && problemQ >= jsonQ
+}
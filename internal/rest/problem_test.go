@@ -0,0 +1,38 @@
+package rest
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestPrefersProblem(t *testing.T) {
+	cases := map[string]bool{
+		"":                         false,
+		"*/*":                      false,
+		"application/json":         false,
+		"application/problem+json": true,
+		"application/json, application/problem+json":       true,
+		"application/problem+json;q=0.5, application/json": false,
+		"application/problem+json, application/json;q=0.9": true,
+		"application/problem+json;q=0":                     false,
+	}
+	for accept, expected := range cases {
+		if got := PrefersProblem(accept); got != expected {
+			t.Fatalf("PrefersProblem(%q) = %v, expected %v", accept, got, expected)
+		}
+	}
+}
+
+func TestRestError_Problem_ShouldMapConstructorsToDocumentedTypes(t *testing.T) {
+	problem := NewNotFoundError("task not found").Problem("/api/v1/tasks/42")
+
+	if problem.Type != ProblemTypeBaseURI+"not-found" {
+		t.Fatalf("Unexpected problem type %q", problem.Type)
+	}
+	if problem.Title != "Not Found" || problem.Status != http.StatusNotFound {
+		t.Fatalf("Unexpected title or status: %+v", problem)
+	}
+	if problem.Detail != "task not found" || problem.Instance != "/api/v1/tasks/42" {
+		t.Fatalf("Unexpected detail or instance: %+v", problem)
+	}
+}
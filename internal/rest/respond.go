@@ -0,0 +1,70 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	domain "github.com/felipeversiane/task-api/internal"
+	"github.com/felipeversiane/task-api/internal/log"
+	"github.com/google/uuid"
+)
+
+func ExtractIDFromPath(r *http.Request) (uuid.UUID, error) {
+	return uuid.Parse(r.PathValue("id"))
+}
+
+// ValidationError merges domain validation errors, reporting each field once.
+func ValidationError(errs ...error) *RestError {
+	var causes []Cause
+	var messages []string
+	reported := map[string]bool{}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		var fieldErrs domain.ValidationErrors
+		if !errors.As(err, &fieldErrs) {
+			return NewBadRequestError(err.Error())
+		}
+		for _, fieldErr := range fieldErrs {
+			if reported[fieldErr.Field] {
+				continue
+			}
+			reported[fieldErr.Field] = true
+			causes = append(causes, Cause{Field: fieldErr.Field, Code: fieldErr.Code, Message: fieldErr.Message})
+			messages = append(messages, fieldErr.Message)
+		}
+	}
+
+	if len(causes) == 0 {
+		return nil
+	}
+	return NewValidationError(strings.Join(messages, "; "), causes)
+}
+
+func RespondWithError(w http.ResponseWriter, r *http.Request, err *RestError) {
+	if err.Code >= http.StatusInternalServerError {
+		err.RequestID = log.RequestID(r.Context())
+	}
+
+	w.Header().Add("Vary", "Accept")
+	if PrefersProblem(r.Header.Get("Accept")) {
+		RespondWithContent(w, err.Code, ProblemContentType, err.Problem(r.URL.RequestURI()))
+		return
+	}
+	RespondWithJSON(w, err.Code, err)
+}
+
+func RespondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	RespondWithContent(w, code, "application/json", payload)
+}
+
+func RespondWithContent(w http.ResponseWriter, code int, contentType string, payload interface{}) {
+	response, _ := json.Marshal(payload)
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(code)
+	w.Write(response)
+}
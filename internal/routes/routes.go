@@ -1,16 +1,43 @@
 package routes
 
 import (
+	"expvar"
 	"net/http"
 
+	"github.com/felipeversiane/task-api/internal/cache"
+	"github.com/felipeversiane/task-api/internal/config"
+	"github.com/felipeversiane/task-api/internal/database"
+	"github.com/felipeversiane/task-api/internal/health"
+	"github.com/felipeversiane/task-api/internal/metrics"
+	"github.com/felipeversiane/task-api/internal/stream"
 	"github.com/felipeversiane/task-api/internal/task"
+	"github.com/felipeversiane/task-api/internal/webhook"
 )
 
-func SetupRoutes(mux *http.ServeMux) {
+func SetupRoutes(mux *http.ServeMux, broker *stream.Broker, webhookConfig config.WebhookConfig) {
 
-	task.TasksRouter(mux)
+	webhook.WebhooksRouter(mux, webhookConfig)
+	task.TasksRouter(mux, broker)
 
+	database.RegisterMetrics(metrics.Default)
+
+	mux.HandleFunc("GET /livez", health.Liveness)
+	mux.HandleFunc("GET /readyz", health.Readiness(healthChecks()...))
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
 }
+
+func SetupAdminRoutes(mux *http.ServeMux) {
+	mux.Handle("GET /debug/vars", expvar.Handler())
+	mux.Handle("GET /metrics", metrics.Handler())
+	mux.HandleFunc("GET /debug/readyz", health.Details(healthChecks()...))
+}
+
+func healthChecks() []health.Check {
+	checks := []health.Check{health.PostgresCheck(database.Connection)}
+	if cache.Client != nil {
+		checks = append(checks, health.RedisCheck(cache.Client))
+	}
+	return checks
+}
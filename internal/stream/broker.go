@@ -0,0 +1,216 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/felipeversiane/task-api/internal/config"
+	"github.com/felipeversiane/task-api/internal/log"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	EventCreated = "created"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+	EventReset   = "reset"
+
+	sequenceKey       = "task:events:sequence"
+	subscriberBacklog = 64
+)
+
+type Event struct {
+	ID   uint64          `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+type Subscriber struct {
+	events chan Event
+}
+
+func (s *Subscriber) Events() <-chan Event {
+	return s.events
+}
+
+type Broker struct {
+	Client      *redis.Client
+	Channel     string
+	Heartbeat   time.Duration
+	mu          sync.Mutex
+	sequence    uint64
+	buffer      []Event
+	next        int
+	subscribers map[*Subscriber]struct{}
+	closed      bool
+}
+
+func NewBroker(client *redis.Client, cfg config.StreamConfig) *Broker {
+	return &Broker{
+		Client:      client,
+		Channel:     cfg.Channel,
+		Heartbeat:   cfg.Heartbeat,
+		buffer:      make([]Event, 0, cfg.BufferSize),
+		subscribers: map[*Subscriber]struct{}{},
+	}
+}
+
+func (b *Broker) Publish(ctx context.Context, eventType string, aggregateID uuid.UUID, payload interface{}) {
+	logger := log.FromContext(ctx).With(slog.String("event_type", eventType), slog.String("aggregate_id", aggregateID.String()))
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error("Failed to marshal stream event", slog.Any("error", err))
+		return
+	}
+
+	if b.Client == nil {
+		b.mu.Lock()
+		b.sequence++
+		event := Event{ID: b.sequence, Type: eventType, Data: data}
+		b.mu.Unlock()
+		b.dispatch(event)
+		return
+	}
+
+	sequence, err := b.Client.Incr(ctx, sequenceKey).Uint64()
+	if err != nil {
+		logger.Error("Failed to allocate stream event ID", slog.Any("error", err))
+		return
+	}
+
+	message, _ := json.Marshal(Event{ID: sequence, Type: eventType, Data: data})
+	if err := b.Client.Publish(ctx, b.Channel, message).Err(); err != nil {
+		logger.Error("Failed to publish stream event", slog.Any("error", err))
+	}
+}
+
+func (b *Broker) Run(ctx context.Context) {
+	if b.Client == nil {
+		return
+	}
+
+	pubsub := b.Client.Subscribe(ctx, b.Channel)
+	defer pubsub.Close()
+	slog.Info("Task event stream subscribed", slog.String("channel", b.Channel))
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+			var event Event
+			if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+				slog.Error("Failed to decode stream event", slog.Any("error", err))
+				continue
+			}
+			b.dispatch(event)
+		}
+	}
+}
+
+func (b *Broker) Subscribe(ctx context.Context, lastEventID string) (*Subscriber, []Event, bool) {
+	complete := true
+	var lastID, latest uint64
+	if lastEventID != "" {
+		var err error
+		lastID, err = strconv.ParseUint(lastEventID, 10, 64)
+		complete = err == nil
+		if complete && b.Client != nil {
+			latest, err = b.Client.Get(ctx, sequenceKey).Uint64()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				log.FromContext(ctx).Error("Failed to read stream sequence", slog.Any("error", err))
+				complete = false
+			}
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &Subscriber{events: make(chan Event, subscriberBacklog)}
+	if b.closed {
+		close(sub.events)
+		return sub, nil, true
+	}
+	b.subscribers[sub] = struct{}{}
+
+	if lastEventID == "" || !complete {
+		return sub, nil, complete
+	}
+	if b.Client == nil {
+		latest = b.sequence
+	}
+	replay, complete := b.since(lastID, latest)
+	return sub, replay, complete
+}
+
+func (b *Broker) Unsubscribe(sub *Subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+func (b *Broker) dispatch(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.buffer) < cap(b.buffer) {
+		b.buffer = append(b.buffer, event)
+	} else if len(b.buffer) > 0 {
+		b.buffer[b.next] = event
+		b.next = (b.next + 1) % len(b.buffer)
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+func (b *Broker) since(lastID uint64, latest uint64) ([]Event, bool) {
+	ordered := append(append([]Event{}, b.buffer[b.next:]...), b.buffer[:b.next]...)
+
+	var replay []Event
+	var oldest uint64
+	for _, event := range ordered {
+		if oldest == 0 || event.ID < oldest {
+			oldest = event.ID
+		}
+		if event.ID > lastID {
+			replay = append(replay, event)
+		}
+	}
+
+	complete := lastID >= latest || oldest != 0 && oldest <= lastID+1
+	return replay, complete
+}
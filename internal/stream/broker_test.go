@@ -0,0 +1,68 @@
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/felipeversiane/task-api/internal/config"
+	"github.com/google/uuid"
+)
+
+func newTestBroker(bufferSize int) *Broker {
+	return NewBroker(nil, config.StreamConfig{Channel: "test", BufferSize: bufferSize, Heartbeat: time.Second})
+}
+
+func TestBroker_ShouldReplayEventsAfterLastEventID(t *testing.T) {
+	broker := newTestBroker(2)
+	for i := 0; i < 4; i++ {
+		broker.Publish(context.Background(), EventUpdated, uuid.New(), map[string]int{"n": i})
+	}
+
+	sub, replay, complete := broker.Subscribe(context.Background(), "2")
+	defer broker.Unsubscribe(sub)
+
+	if !complete {
+		t.Fatal("Expected replay to be complete")
+	}
+	if len(replay) != 2 || replay[0].ID != 3 || replay[1].ID != 4 {
+		t.Fatalf("Expected events 3 and 4 and received %+v", replay)
+	}
+
+	broker.Publish(context.Background(), EventDeleted, uuid.New(), nil)
+	if event := <-sub.Events(); event.ID != 5 || event.Type != EventDeleted {
+		t.Fatalf("Expected live event 5 and received %+v", event)
+	}
+}
+
+func TestBroker_ShouldReportIncompleteReplay_WhenEventsWereEvicted(t *testing.T) {
+	broker := newTestBroker(2)
+	for i := 0; i < 4; i++ {
+		broker.Publish(context.Background(), EventCreated, uuid.New(), nil)
+	}
+
+	for _, lastEventID := range []string{"1", "not-a-number"} {
+		sub, _, complete := broker.Subscribe(context.Background(), lastEventID)
+		broker.Unsubscribe(sub)
+		if complete {
+			t.Fatalf("Expected incomplete replay for Last-Event-ID %q", lastEventID)
+		}
+	}
+}
+
+func TestBroker_ShouldDropSlowSubscribers(t *testing.T) {
+	broker := newTestBroker(10)
+	sub, _, _ := broker.Subscribe(context.Background(), "")
+
+	for i := 0; i <= subscriberBacklog; i++ {
+		broker.Publish(context.Background(), EventCreated, uuid.New(), nil)
+	}
+
+	received := 0
+	for range sub.Events() {
+		received++
+	}
+	if received != subscriberBacklog {
+		t.Fatalf("Expected %d buffered events before the drop and received %d", subscriberBacklog, received)
+	}
+}
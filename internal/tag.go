@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const maxTagLength = 32
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+type Tag struct {
+	ID        uuid.UUID
+	Name      string
+	CreatedAt time.Time
+}
+
+func NewTag(name string) Tag {
+	return Tag{
+		ID:        uuid.New(),
+		Name:      NormalizeTagName(name),
+		CreatedAt: time.Now(),
+	}
+}
+
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func ValidateTagName(field string, name string) error {
+	var errs ValidationErrors
+	switch {
+	case name == "":
+		errs = errs.Add(field, CodeRequired, "tag name is required")
+	case len(name) > maxTagLength:
+		errs = errs.Add(field, CodeTooLong, "tag name must have a maximum of 32 characters")
+	case !tagPattern.MatchString(name):
+		errs = errs.Add(field, CodeInvalidFormat, "tag name may only contain lowercase letters, digits, '.', '_' and '-'")
+	}
+	return errs.Err()
+}
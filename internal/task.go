@@ -2,6 +2,7 @@ package domain
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,37 +14,156 @@ const (
 	SituationInProgress = "in progress"
 	SituationCompleted  = "completed"
 	SituationNotStarted = "not started"
+	SituationBlocked    = "blocked"
+	SituationCancelled  = "cancelled"
+	SituationReopened   = "reopened"
 )
 
 var validSituations = map[Situation]bool{
 	SituationInProgress: true,
 	SituationCompleted:  true,
 	SituationNotStarted: true,
+	SituationBlocked:    true,
+	SituationCancelled:  true,
+	SituationReopened:   true,
+}
+
+var initialSituations = map[Situation]bool{
+	SituationInProgress: true,
+	SituationCompleted:  true,
+	SituationNotStarted: true,
+}
+
+var transitions = map[Situation][]Situation{
+	SituationNotStarted: {SituationInProgress, SituationBlocked, SituationCancelled},
+	SituationInProgress: {SituationCompleted, SituationBlocked, SituationCancelled},
+	SituationBlocked:    {SituationInProgress, SituationCancelled},
+	SituationCompleted:  {SituationReopened},
+	SituationCancelled:  {SituationReopened},
+	SituationReopened:   {SituationInProgress, SituationBlocked, SituationCancelled},
+}
+
+var ErrInvalidTransition = errors.New("invalid situation transition")
+
+type Priority string
+
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+
+	DefaultPriority = PriorityMedium
+)
+
+var priorityRanks = map[Priority]int{
+	PriorityLow:    1,
+	PriorityMedium: 2,
+	PriorityHigh:   3,
+	PriorityUrgent: 4,
+}
+
+var closedSituations = map[Situation]bool{
+	SituationCompleted: true,
+	SituationCancelled: true,
 }
 
 func IsValidSituation(s Situation) bool {
 	return validSituations[s]
 }
 
+func IsValidInitialSituation(s Situation) bool {
+	return initialSituations[s]
+}
+
+func IsClosedSituation(s Situation) bool {
+	return closedSituations[s]
+}
+
+func ClosedSituations() []Situation {
+	return []Situation{SituationCompleted, SituationCancelled}
+}
+
+func IsValidPriority(p Priority) bool {
+	return priorityRanks[p] > 0
+}
+
+func PriorityRank(p Priority) int {
+	return priorityRanks[p]
+}
+
+func PriorityFromRank(rank int) (Priority, bool) {
+	for priority, r := range priorityRanks {
+		if r == rank {
+			return priority, true
+		}
+	}
+	return "", false
+}
+
+func IsOverdue(dueAt *time.Time, situation Situation, now time.Time) bool {
+	return dueAt != nil && dueAt.Before(now) && !IsClosedSituation(situation)
+}
+
+func CanTransition(from, to Situation) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func AllowedTransitions(from Situation) []Situation {
+	return append([]Situation(nil), transitions[from]...)
+}
+
+func ValidateTransition(from, to Situation) error {
+	if !IsValidSituation(to) {
+		return errors.New("invalid situation value")
+	}
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: cannot move from %q to %q", ErrInvalidTransition, from, to)
+	}
+	return nil
+}
+
 type Task struct {
 	ID          uuid.UUID
 	Name        string
 	Description string
 	Situation   Situation
+	Priority    Priority
+	DueAt       *time.Time
+	Version     int64
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
 
+type Transition struct {
+	ID        uuid.UUID
+	TaskID    uuid.UUID
+	From      Situation
+	To        Situation
+	Reason    string
+	CreatedAt time.Time
+}
+
 func NewTask(
 	name string,
 	description string,
 	situation Situation,
+	priority Priority,
+	dueAt *time.Time,
 ) Task {
 	return Task{
 		ID:          uuid.New(),
 		Name:        name,
 		Description: description,
 		Situation:   situation,
+		Priority:    defaultPriority(priority),
+		DueAt:       normalizeDueAt(dueAt),
+		Version:     1,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
@@ -53,30 +173,75 @@ func NewUpdateTask(
 	name string,
 	description string,
 	situation Situation,
+	priority Priority,
+	dueAt *time.Time,
 ) Task {
 	return Task{
 		Name:        name,
 		Description: description,
 		Situation:   situation,
+		Priority:    defaultPriority(priority),
+		DueAt:       normalizeDueAt(dueAt),
 		UpdatedAt:   time.Now(),
 	}
 }
 
-func (t *Task) ValidateFields() error {
-	if t.Name == "" {
-		return errors.New("name cannot be empty")
+func NewTransition(
+	taskID uuid.UUID,
+	from Situation,
+	to Situation,
+	reason string,
+) Transition {
+	return Transition{
+		ID:        uuid.New(),
+		TaskID:    taskID,
+		From:      from,
+		To:        to,
+		Reason:    reason,
+		CreatedAt: time.Now(),
 	}
-	if len(t.Name) < 3 {
-		return errors.New("name must be at least 3 characters long")
+}
+
+func (t *Task) ValidateFields() error {
+	var errs ValidationErrors
+	switch {
+	case t.Name == "":
+		errs = errs.Add("name", CodeRequired, "name cannot be empty")
+	case len(t.Name) < 3:
+		errs = errs.Add("name", CodeTooShort, "name must be at least 3 characters long")
+	case len(t.Name) > 32:
+		errs = errs.Add("name", CodeTooLong, "name must have a maximum of 32 characters")
 	}
 	if len(t.Description) > 255 {
-		return errors.New("description must have a maximum of 255 characters")
-	}
-	if len(t.Name) > 32 {
-		return errors.New("name must have a maximum of 32 characters")
+		errs = errs.Add("description", CodeTooLong, "description must have a maximum of 255 characters")
 	}
 	if !IsValidSituation(t.Situation) {
-		return errors.New("invalid situation value")
+		errs = errs.Add("situation", CodeInvalidEnum, "invalid situation value")
+	}
+	if !IsValidPriority(t.Priority) {
+		errs = errs.Add("priority", CodeInvalidEnum, "priority must be one of low, medium, high, urgent")
+	}
+	return errs.Err()
+}
+
+func (t *Task) ValidateInitialSituation() error {
+	if !IsValidInitialSituation(t.Situation) {
+		return ValidationErrors{}.Add("situation", CodeInvalidEnum, "invalid situation value")
 	}
 	return nil
 }
+
+func defaultPriority(priority Priority) Priority {
+	if priority == "" {
+		return DefaultPriority
+	}
+	return priority
+}
+
+func normalizeDueAt(dueAt *time.Time) *time.Time {
+	if dueAt == nil {
+		return nil
+	}
+	utc := dueAt.UTC()
+	return &utc
+}
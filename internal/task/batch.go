@@ -0,0 +1,120 @@
+package task
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	domain "github.com/felipeversiane/task-api/internal"
+	"github.com/felipeversiane/task-api/internal/rest"
+	"github.com/google/uuid"
+)
+
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+
+	maxBatchOperations = 500
+)
+
+type BatchRequest struct {
+	Atomic     bool                    `json:"atomic"`
+	Operations []BatchOperationRequest `json:"operations"`
+}
+
+type BatchOperationRequest struct {
+	Op          string           `json:"op"`
+	ID          string           `json:"id,omitempty"`
+	Version     int64            `json:"version,omitempty"`
+	Name        string           `json:"name,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Situation   domain.Situation `json:"situation,omitempty"`
+	Priority    *domain.Priority `json:"priority,omitempty"`
+	DueAt       *time.Time       `json:"due_at,omitempty"`
+}
+
+type BatchOperation struct {
+	Index   int
+	Op      string
+	Version int64
+	Task    domain.Task
+	Update  UpdateTaskRequest
+	Current *TaskResponse
+}
+
+type BatchResult struct {
+	Index  int             `json:"index"`
+	Op     string          `json:"op"`
+	Status int             `json:"status"`
+	Task   *TaskResponse   `json:"task,omitempty"`
+	Error  *rest.RestError `json:"error,omitempty"`
+}
+
+type BatchResponse struct {
+	Results []BatchResult `json:"results"`
+}
+
+func prepareBatchOperation(index int, req BatchOperationRequest) (BatchOperation, *rest.RestError) {
+	op := BatchOperation{Index: index, Op: req.Op, Version: req.Version}
+
+	switch req.Op {
+	case BatchCreate:
+		create := TaskRequest{Name: req.Name, Description: req.Description, Situation: req.Situation, DueAt: req.DueAt}
+		if req.Priority != nil {
+			create.Priority = *req.Priority
+		}
+		op.Task = RequestToDomainTask(create)
+		if err := rest.ValidationError(create.Validate(), op.Task.ValidateFields(), op.Task.ValidateInitialSituation()); err != nil {
+			return op, err
+		}
+	case BatchUpdate:
+		id, err := uuid.Parse(req.ID)
+		if err != nil {
+			return op, rest.NewBadRequestError("invalid task ID")
+		}
+		op.Update = UpdateTaskRequest{Name: req.Name, Description: req.Description, Situation: req.Situation, Priority: req.Priority, DueAt: req.DueAt}
+		op.Task = RequestToUpdateDomainTask(op.Update)
+		op.Task.ID = id
+		if err := rest.ValidationError(op.Update.Validate(), op.Task.ValidateFields()); err != nil {
+			return op, err
+		}
+	case BatchDelete:
+		id, err := uuid.Parse(req.ID)
+		if err != nil {
+			return op, rest.NewBadRequestError("invalid task ID")
+		}
+		op.Task = domain.Task{ID: id}
+	default:
+		return op, rest.NewBadRequestError(fmt.Sprintf("unknown batch operation %q", req.Op))
+	}
+	return op, nil
+}
+
+func batchSuccess(op BatchOperation, task *TaskResponse) BatchResult {
+	status := http.StatusOK
+	switch op.Op {
+	case BatchCreate:
+		status = http.StatusCreated
+	case BatchDelete:
+		status, task = http.StatusNoContent, nil
+	}
+	return BatchResult{Index: op.Index, Op: op.Op, Status: status, Task: task}
+}
+
+func batchFailure(index int, op string, err *rest.RestError) BatchResult {
+	return BatchResult{Index: index, Op: op, Status: err.Code, Error: err}
+}
+
+func batchError(index int, err *rest.RestError) *rest.RestError {
+	failed := *err
+	failed.Message = fmt.Sprintf("operation %d: %s", index, err.Message)
+	return &failed
+}
+
+func batchMissError(op BatchOperation) *rest.RestError {
+	if op.Op == BatchCreate {
+		return rest.NewConflictError(fmt.Sprintf("task with name %s already exists", op.Task.Name))
+	}
+	return rest.NewPreconditionFailedError(fmt.Sprintf("task with ID %s has been modified", op.Task.ID))
+}
@@ -1,8 +1,7 @@
 package task
 
 import (
-	"fmt"
-	"strings"
+	"encoding/json"
 	"time"
 
 	domain "github.com/felipeversiane/task-api/internal"
@@ -13,12 +12,22 @@ type TaskRequest struct {
 	Name        string           `json:"name"`
 	Description string           `json:"description"`
 	Situation   domain.Situation `json:"situation"`
+	Priority    domain.Priority  `json:"priority"`
+	DueAt       *time.Time       `json:"due_at"`
 }
 
+// UpdateTaskRequest replaces a task. Priority and DueAt keep their stored values when omitted.
 type UpdateTaskRequest struct {
 	Name        string           `json:"name"`
 	Description string           `json:"description"`
 	Situation   domain.Situation `json:"situation"`
+	Priority    *domain.Priority `json:"priority"`
+	DueAt       *time.Time       `json:"due_at"`
+}
+
+type TransitionRequest struct {
+	Situation domain.Situation `json:"situation"`
+	Reason    string           `json:"reason"`
 }
 
 type TaskResponse struct {
@@ -26,44 +35,70 @@ type TaskResponse struct {
 	Name        string           `json:"name"`
 	Description string           `json:"description"`
 	Situation   domain.Situation `json:"situation"`
+	Priority    domain.Priority  `json:"priority"`
+	DueAt       *time.Time       `json:"due_at"`
+	Tags        []string         `json:"tags"`
+	Version     int64            `json:"version"`
 	CreatedAt   time.Time        `json:"created_at"`
 	UpdatedAt   time.Time        `json:"updated_at"`
 }
 
+type TransitionResponse struct {
+	ID        uuid.UUID        `json:"id"`
+	TaskID    uuid.UUID        `json:"task_id"`
+	From      domain.Situation `json:"from"`
+	To        domain.Situation `json:"to"`
+	Reason    string           `json:"reason,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+func (t TaskResponse) IsOverdue(now time.Time) bool {
+	return domain.IsOverdue(t.DueAt, t.Situation, now)
+}
+
+// storedTask is TaskResponse without the overdue flag.
+type storedTask TaskResponse
+
+func (t TaskResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		storedTask
+		Overdue bool `json:"overdue"`
+	}{storedTask(t), t.IsOverdue(time.Now())})
+}
+
 func (req *TaskRequest) Validate() error {
-	var missingFields []string
-	if req.Name == "" {
-		missingFields = append(missingFields, "name")
-	}
-	if req.Description == "" {
-		missingFields = append(missingFields, "description")
-	}
+	return validateRequired(req.Name, req.Description, req.Situation).Err()
+}
+
+func (req *UpdateTaskRequest) Validate() error {
+	return validateRequired(req.Name, req.Description, req.Situation).Err()
+}
+
+func (req *TransitionRequest) Validate() error {
+	var errs domain.ValidationErrors
 	if req.Situation == "" {
-		missingFields = append(missingFields, "situation")
+		errs = errs.Add("situation", domain.CodeRequired, "situation is required")
+	} else if !domain.IsValidSituation(req.Situation) {
+		errs = errs.Add("situation", domain.CodeInvalidEnum, "invalid situation value")
 	}
-
-	if len(missingFields) > 0 {
-		return fmt.Errorf("missing required fields: %s", strings.Join(missingFields, ", "))
+	if len(req.Reason) > 255 {
+		errs = errs.Add("reason", domain.CodeTooLong, "reason must have a maximum of 255 characters")
 	}
-	return nil
+	return errs.Err()
 }
 
-func (req *UpdateTaskRequest) Validate() error {
-	var missingFields []string
-	if req.Name == "" {
-		missingFields = append(missingFields, "name")
+func validateRequired(name, description string, situation domain.Situation) domain.ValidationErrors {
+	var errs domain.ValidationErrors
+	if name == "" {
+		errs = errs.Add("name", domain.CodeRequired, "name is required")
 	}
-	if req.Description == "" {
-		missingFields = append(missingFields, "description")
+	if description == "" {
+		errs = errs.Add("description", domain.CodeRequired, "description is required")
 	}
-	if req.Situation == "" {
-		missingFields = append(missingFields, "situation")
+	if situation == "" {
+		errs = errs.Add("situation", domain.CodeRequired, "situation is required")
 	}
-
-	if len(missingFields) > 0 {
-		return fmt.Errorf("missing required fields: %s", strings.Join(missingFields, ", "))
-	}
-	return nil
+	return errs
 }
 
 func RequestToDomainTask(req TaskRequest) domain.Task {
@@ -71,24 +106,56 @@ func RequestToDomainTask(req TaskRequest) domain.Task {
 		req.Name,
 		req.Description,
 		req.Situation,
+		req.Priority,
+		req.DueAt,
 	)
 }
 
 func RequestToUpdateDomainTask(req UpdateTaskRequest) domain.Task {
+	var priority domain.Priority
+	if req.Priority != nil {
+		priority = *req.Priority
+	}
 	return domain.NewUpdateTask(
 		req.Name,
 		req.Description,
 		req.Situation,
+		priority,
+		req.DueAt,
 	)
 }
 
+func keepOmittedFields(task *domain.Task, req UpdateTaskRequest, current *TaskResponse) {
+	if req.Priority == nil {
+		task.Priority = current.Priority
+	}
+	if req.DueAt == nil {
+		task.DueAt = current.DueAt
+	}
+}
+
 func DomainToResponseTask(domain domain.Task) TaskResponse {
 	return TaskResponse{
 		ID:          domain.ID,
 		Name:        domain.Name,
 		Description: domain.Description,
 		Situation:   domain.Situation,
+		Priority:    domain.Priority,
+		DueAt:       domain.DueAt,
+		Tags:        []string{},
+		Version:     domain.Version,
 		CreatedAt:   domain.CreatedAt,
 		UpdatedAt:   domain.UpdatedAt,
 	}
 }
+
+func DomainToResponseTransition(domain domain.Transition) TransitionResponse {
+	return TransitionResponse{
+		ID:        domain.ID,
+		TaskID:    domain.TaskID,
+		From:      domain.From,
+		To:        domain.To,
+		Reason:    domain.Reason,
+		CreatedAt: domain.CreatedAt,
+	}
+}
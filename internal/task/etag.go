@@ -0,0 +1,55 @@
+package task
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/felipeversiane/task-api/internal/rest"
+)
+
+func ETag(task *TaskResponse) string {
+	return fmt.Sprintf(`"%s-%d"`, task.ID, task.Version)
+}
+
+func matchesIfMatch(header string, task *TaskResponse) bool {
+	if header == "" {
+		return true
+	}
+	etag := ETag(task)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesIfNoneMatch(header string, task *TaskResponse) bool {
+	if header == "" {
+		return false
+	}
+	etag := ETag(task)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func checkIfMatch(header string, task *TaskResponse) *rest.RestError {
+	if !matchesIfMatch(header, task) {
+		return rest.NewPreconditionFailedError(fmt.Sprintf("task with ID %s does not match If-Match %s", task.ID, header))
+	}
+	return nil
+}
+
+func modifiedError(err *rest.RestError, ifMatch string) *rest.RestError {
+	if err.Code == http.StatusPreconditionFailed && ifMatch == "" {
+		return rest.NewConflictError(err.Message)
+	}
+	return err
+}
@@ -0,0 +1,71 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/felipeversiane/task-api/internal/database"
+	"github.com/felipeversiane/task-api/internal/outbox"
+	"github.com/felipeversiane/task-api/internal/rest"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type EventPublisher interface {
+	Publish(ctx context.Context, eventType string, aggregateID uuid.UUID, payload interface{})
+}
+
+type TaskChange struct {
+	Before *TaskResponse `json:"before"`
+	After  *TaskResponse `json:"after"`
+}
+
+func (c TaskChange) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Before *storedTask `json:"before"`
+		After  *storedTask `json:"after"`
+	}{(*storedTask)(c.Before), (*storedTask)(c.After)})
+}
+
+// StreamTask is a stream event payload, with the previous task under "before".
+type StreamTask struct {
+	*TaskResponse
+	Before *TaskResponse `json:"before,omitempty"`
+}
+
+func (t StreamTask) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(t.TaskResponse)
+	if err != nil || t.Before == nil {
+		return data, err
+	}
+	before, err := json.Marshal(t.Before)
+	if err != nil {
+		return nil, err
+	}
+	data = append(data[:len(data)-1], `,"before":`...)
+	return append(append(data, before...), '}'), nil
+}
+
+func newTaskEvent(eventType string, before *TaskResponse, after *TaskResponse) (outbox.Event, *rest.RestError) {
+	aggregate := after
+	if aggregate == nil {
+		aggregate = before
+	}
+
+	event, err := outbox.NewEvent(eventType, aggregate.ID, TaskChange{Before: before, After: after})
+	if err != nil {
+		return outbox.Event{}, rest.NewInternalServerError(err.Error())
+	}
+	return event, nil
+}
+
+func writeTaskEvent(ctx context.Context, tx pgx.Tx, eventType string, before *TaskResponse, after *TaskResponse) *rest.RestError {
+	event, restErr := newTaskEvent(eventType, before, after)
+	if restErr != nil {
+		return restErr
+	}
+	if err := outbox.Write(ctx, tx, event); err != nil {
+		return database.MapError(err)
+	}
+	return nil
+}
@@ -2,15 +2,21 @@ package task
 
 import (
 	"encoding/json"
+	"io"
+	"mime"
 	"net/http"
-	"strings"
 
+	"github.com/felipeversiane/task-api/internal/idempotency"
+	"github.com/felipeversiane/task-api/internal/patch"
 	"github.com/felipeversiane/task-api/internal/rest"
-	"github.com/google/uuid"
+	"github.com/felipeversiane/task-api/internal/stream"
+	"github.com/felipeversiane/task-api/internal/tracing"
 )
 
 type TaskHandler struct {
-	Service TaskService
+	Service     TaskService
+	Idempotency idempotency.Store
+	Stream      *stream.Broker
 }
 
 func NewTaskHandler(service TaskService) TaskHandler {
@@ -20,62 +26,102 @@ func NewTaskHandler(service TaskService) TaskHandler {
 }
 
 func (h *TaskHandler) PostTask(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	ctx, span := tracing.Tracer().Start(r.Context(), "TaskHandler.PostTask")
+	defer span.End()
 
 	var req TaskRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		httpErr := rest.NewBadRequestError("invalid request payload")
-		respondWithJSON(w, httpErr.Code, httpErr)
+		rest.RespondWithError(w, r, httpErr)
 		return
 	}
 
 	resp, err := h.Service.CreateTask(ctx, req)
 	if err != nil {
-		respondWithJSON(w, err.Code, err)
+		rest.RespondWithError(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusCreated, resp)
+	respondWithTask(w, http.StatusCreated, resp)
 }
 
 func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	ctx, span := tracing.Tracer().Start(r.Context(), "TaskHandler.UpdateTask")
+	defer span.End()
 
-	id, parseErr := extractIDFromPath(r.URL.Path)
+	id, parseErr := rest.ExtractIDFromPath(r)
 	if parseErr != nil {
 		httpErr := rest.NewBadRequestError("invalid task ID")
-		respondWithJSON(w, httpErr.Code, httpErr)
+		rest.RespondWithError(w, r, httpErr)
 		return
 	}
 
 	var req UpdateTaskRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		httpErr := rest.NewBadRequestError("invalid request payload")
-		respondWithJSON(w, httpErr.Code, httpErr)
+		rest.RespondWithError(w, r, httpErr)
 		return
 	}
 
-	resp, err := h.Service.UpdateTask(ctx, id, req)
+	resp, err := h.Service.UpdateTask(ctx, id, req, r.Header.Get("If-Match"))
 	if err != nil {
-		respondWithJSON(w, err.Code, err)
+		rest.RespondWithError(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, resp)
+	respondWithTask(w, http.StatusOK, resp)
+}
+
+func (h *TaskHandler) PatchTask(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "TaskHandler.PatchTask")
+	defer span.End()
+
+	id, parseErr := rest.ExtractIDFromPath(r)
+	if parseErr != nil {
+		httpErr := rest.NewBadRequestError("invalid task ID")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	contentType, _, mediaErr := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaErr != nil {
+		httpErr := rest.NewUnsupportedMediaTypeError("missing or invalid Content-Type header")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	body, readErr := io.ReadAll(r.Body)
+	if readErr != nil {
+		httpErr := rest.NewBadRequestError("invalid request payload")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	resp, err := h.Service.PatchTask(ctx, id, contentType, body, r.Header.Get("If-Match"))
+	if err != nil {
+		if err.Code == http.StatusUnsupportedMediaType {
+			w.Header().Set("Accept-Patch", patch.MergePatchContentType+", "+patch.JSONPatchContentType)
+		}
+		rest.RespondWithError(w, r, err)
+		return
+	}
+
+	respondWithTask(w, http.StatusOK, resp)
 }
 
 func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	ctx, span := tracing.Tracer().Start(r.Context(), "TaskHandler.DeleteTask")
+	defer span.End()
 
-	id, parseErr := extractIDFromPath(r.URL.Path)
+	id, parseErr := rest.ExtractIDFromPath(r)
 	if parseErr != nil {
 		httpErr := rest.NewBadRequestError("invalid task ID")
-		respondWithJSON(w, httpErr.Code, httpErr)
+		rest.RespondWithError(w, r, httpErr)
 		return
 	}
 
-	if err := h.Service.DeleteTask(ctx, id); err != nil {
-		respondWithJSON(w, err.Code, err)
+	if err := h.Service.DeleteTask(ctx, id, r.Header.Get("If-Match")); err != nil {
+		rest.RespondWithError(w, r, err)
 		return
 	}
 
@@ -83,46 +129,295 @@ func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *TaskHandler) GetTaskByID(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	ctx, span := tracing.Tracer().Start(r.Context(), "TaskHandler.GetTaskByID")
+	defer span.End()
 
-	id, parseErr := extractIDFromPath(r.URL.Path)
+	id, parseErr := rest.ExtractIDFromPath(r)
 	if parseErr != nil {
 		httpErr := rest.NewBadRequestError("invalid task ID")
-		respondWithJSON(w, httpErr.Code, httpErr)
+		rest.RespondWithError(w, r, httpErr)
 		return
 	}
 
 	resp, err := h.Service.GetTaskByID(ctx, id)
 	if err != nil {
-		respondWithJSON(w, err.Code, err)
+		rest.RespondWithError(w, r, err)
+		return
+	}
+
+	if matchesIfNoneMatch(r.Header.Get("If-None-Match"), resp) {
+		w.Header().Set("ETag", ETag(resp))
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, resp)
+	respondWithTask(w, http.StatusOK, resp)
 }
 
 func (h *TaskHandler) GetAllTasks(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	ctx, span := tracing.Tracer().Start(r.Context(), "TaskHandler.GetAllTasks")
+	defer span.End()
+
+	query, parseErr := ParseTaskListQuery(r.URL.Query())
+	if parseErr != nil {
+		httpErr := rest.NewBadRequestError(parseErr.Error())
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	resp, err := h.Service.GetAllTasks(ctx, query)
+	if err != nil {
+		rest.RespondWithError(w, r, err)
+		return
+	}
+
+	rest.RespondWithJSON(w, http.StatusOK, resp)
+}
+
+func (h *TaskHandler) SearchTasks(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "TaskHandler.SearchTasks")
+	defer span.End()
+
+	query, parseErr := ParseTaskSearchQuery(r.URL.Query())
+	if parseErr != nil {
+		httpErr := rest.NewBadRequestError(parseErr.Error())
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	resp, err := h.Service.SearchTasks(ctx, query)
+	if err != nil {
+		rest.RespondWithError(w, r, err)
+		return
+	}
+
+	rest.RespondWithJSON(w, http.StatusOK, resp)
+}
+
+func (h *TaskHandler) TransitionTask(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "TaskHandler.TransitionTask")
+	defer span.End()
+
+	id, parseErr := rest.ExtractIDFromPath(r)
+	if parseErr != nil {
+		httpErr := rest.NewBadRequestError("invalid task ID")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	var req TransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpErr := rest.NewBadRequestError("invalid request payload")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	resp, err := h.Service.TransitionTask(ctx, id, req)
+	if err != nil {
+		rest.RespondWithError(w, r, err)
+		return
+	}
+
+	respondWithTask(w, http.StatusOK, resp)
+}
+
+func (h *TaskHandler) GetTaskTransitions(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "TaskHandler.GetTaskTransitions")
+	defer span.End()
+
+	id, parseErr := rest.ExtractIDFromPath(r)
+	if parseErr != nil {
+		httpErr := rest.NewBadRequestError("invalid task ID")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	resp, err := h.Service.GetTaskTransitions(ctx, id)
+	if err != nil {
+		rest.RespondWithError(w, r, err)
+		return
+	}
+
+	rest.RespondWithJSON(w, http.StatusOK, resp)
+}
+
+func (h *TaskHandler) BatchTasks(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "TaskHandler.BatchTasks")
+	defer span.End()
+
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpErr := rest.NewBadRequestError("invalid request payload")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	resp, err := h.Service.BatchTasks(ctx, req)
+	if err != nil {
+		rest.RespondWithError(w, r, err)
+		return
+	}
+
+	rest.RespondWithJSON(w, http.StatusOK, resp)
+}
+
+func (h *TaskHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	if h.Stream == nil {
+		httpErr := rest.NewServiceUnavailableError("task event stream is not available")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	h.Stream.ServeHTTP(w, r)
+}
+
+func (h *TaskHandler) AttachTags(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "TaskHandler.AttachTags")
+	defer span.End()
+
+	id, parseErr := rest.ExtractIDFromPath(r)
+	if parseErr != nil {
+		httpErr := rest.NewBadRequestError("invalid task ID")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	var req AttachTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpErr := rest.NewBadRequestError("invalid request payload")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	resp, err := h.Service.AttachTags(ctx, id, req, r.Header.Get("If-Match"))
+	if err != nil {
+		rest.RespondWithError(w, r, err)
+		return
+	}
 
-	resp, err := h.Service.GetAllTasks(ctx)
+	respondWithTask(w, http.StatusOK, resp)
+}
+
+func (h *TaskHandler) DetachTag(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "TaskHandler.DetachTag")
+	defer span.End()
+
+	id, parseErr := rest.ExtractIDFromPath(r)
+	if parseErr != nil {
+		httpErr := rest.NewBadRequestError("invalid task ID")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	resp, err := h.Service.DetachTag(ctx, id, r.PathValue("tag"), r.Header.Get("If-Match"))
 	if err != nil {
-		respondWithJSON(w, err.Code, err)
+		rest.RespondWithError(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, resp)
+	respondWithTask(w, http.StatusOK, resp)
 }
 
-func extractIDFromPath(path string) (uuid.UUID, error) {
-	parts := strings.Split(path, "/")
-	idStr := parts[len(parts)-1]
-	return uuid.Parse(idStr)
+func (h *TaskHandler) PostTag(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "TaskHandler.PostTag")
+	defer span.End()
+
+	var req TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpErr := rest.NewBadRequestError("invalid request payload")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	resp, err := h.Service.CreateTag(ctx, req)
+	if err != nil {
+		rest.RespondWithError(w, r, err)
+		return
+	}
+
+	rest.RespondWithJSON(w, http.StatusCreated, resp)
 }
 
-func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
-	response, _ := json.Marshal(payload)
+func (h *TaskHandler) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "TaskHandler.UpdateTag")
+	defer span.End()
+
+	id, parseErr := rest.ExtractIDFromPath(r)
+	if parseErr != nil {
+		httpErr := rest.NewBadRequestError("invalid tag ID")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	var req TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpErr := rest.NewBadRequestError("invalid request payload")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	resp, err := h.Service.RenameTag(ctx, id, req)
+	if err != nil {
+		rest.RespondWithError(w, r, err)
+		return
+	}
+
+	rest.RespondWithJSON(w, http.StatusOK, resp)
+}
+
+func (h *TaskHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "TaskHandler.DeleteTag")
+	defer span.End()
+
+	id, parseErr := rest.ExtractIDFromPath(r)
+	if parseErr != nil {
+		httpErr := rest.NewBadRequestError("invalid tag ID")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	if err := h.Service.DeleteTag(ctx, id); err != nil {
+		rest.RespondWithError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TaskHandler) GetTagByID(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "TaskHandler.GetTagByID")
+	defer span.End()
+
+	id, parseErr := rest.ExtractIDFromPath(r)
+	if parseErr != nil {
+		httpErr := rest.NewBadRequestError("invalid tag ID")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	resp, err := h.Service.GetTagByID(ctx, id)
+	if err != nil {
+		rest.RespondWithError(w, r, err)
+		return
+	}
+
+	rest.RespondWithJSON(w, http.StatusOK, resp)
+}
+
+func (h *TaskHandler) GetAllTags(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "TaskHandler.GetAllTags")
+	defer span.End()
+
+	resp, err := h.Service.GetAllTags(ctx)
+	if err != nil {
+		rest.RespondWithError(w, r, err)
+		return
+	}
+
+	rest.RespondWithJSON(w, http.StatusOK, resp)
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	w.Write(response)
+func respondWithTask(w http.ResponseWriter, code int, task *TaskResponse) {
+	w.Header().Set("ETag", ETag(task))
+	rest.RespondWithJSON(w, code, task)
 }
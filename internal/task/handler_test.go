@@ -0,0 +1,734 @@
+package task
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	domain "github.com/felipeversiane/task-api/internal"
+	"github.com/felipeversiane/task-api/internal/cache"
+	"github.com/felipeversiane/task-api/internal/config"
+	"github.com/felipeversiane/task-api/internal/idempotency"
+	"github.com/felipeversiane/task-api/internal/rest"
+	"github.com/felipeversiane/task-api/internal/stream"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return newTestServerWithIdempotency(t, idempotency.NewMemoryStore())
+}
+
+func newTestServerWithIdempotency(t *testing.T, store idempotency.Store) *httptest.Server {
+	t.Helper()
+
+	broker := stream.NewBroker(nil, config.StreamConfig{Channel: "test", BufferSize: 10, Heartbeat: time.Second})
+	repository := NewTaskRepository(NewMemoryStore(), cache.NewLRUCache(100))
+	repository.Events = broker
+	handler := NewTaskHandler(NewTaskService(&repository))
+	handler.Idempotency = store
+	handler.Stream = broker
+	mux := http.NewServeMux()
+	RegisterRoutes(mux, &handler)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func doRequest(t *testing.T, server *httptest.Server, method string, path string, headers map[string]string, payload interface{}) *http.Response {
+	t.Helper()
+
+	var body bytes.Buffer
+	if payload != nil {
+		if err := json.NewEncoder(&body).Encode(payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req, err := http.NewRequest(method, server.URL+"/api/v1"+path, &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func decodeBody(t *testing.T, resp *http.Response, target interface{}) {
+	t.Helper()
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertStatusCode(t *testing.T, resp *http.Response, expected int) {
+	t.Helper()
+	if resp.StatusCode != expected {
+		t.Fatalf("Invalid Status Code. Expected Status \"%d\" and received \"%s\"", expected, resp.Status)
+	}
+}
+
+func createTask(t *testing.T, server *httptest.Server, name string) TaskResponse {
+	t.Helper()
+
+	resp := doRequest(t, server, http.MethodPost, "/tasks", nil, map[string]interface{}{
+		"name":        name,
+		"description": "A beautiful task to do.",
+		"situation":   "not started",
+	})
+	assertStatusCode(t, resp, http.StatusCreated)
+
+	var task TaskResponse
+	decodeBody(t, resp, &task)
+	return task
+}
+
+func TestTaskFlow_ShouldCreateGetUpdateAndDelete(t *testing.T) {
+	server := newTestServer(t)
+	task := createTask(t, server, "Beautiful task.")
+
+	resp := doRequest(t, server, http.MethodGet, "/tasks/"+task.ID.String(), nil, nil)
+	assertStatusCode(t, resp, http.StatusOK)
+	etag := resp.Header.Get("ETag")
+
+	resp = doRequest(t, server, http.MethodPut, "/tasks/"+task.ID.String(), map[string]string{"If-Match": etag}, map[string]interface{}{
+		"name":        task.Name,
+		"description": "Updated description.",
+		"situation":   "in progress",
+	})
+	assertStatusCode(t, resp, http.StatusOK)
+
+	var updated TaskResponse
+	decodeBody(t, resp, &updated)
+	if updated.Version != task.Version+1 {
+		t.Fatalf("Expected version %d and received %d", task.Version+1, updated.Version)
+	}
+
+	resp = doRequest(t, server, http.MethodDelete, "/tasks/"+task.ID.String(), map[string]string{"If-Match": etag}, nil)
+	assertStatusCode(t, resp, http.StatusPreconditionFailed)
+
+	resp = doRequest(t, server, http.MethodDelete, "/tasks/"+task.ID.String(), nil, nil)
+	assertStatusCode(t, resp, http.StatusNoContent)
+
+	resp = doRequest(t, server, http.MethodGet, "/tasks/"+task.ID.String(), nil, nil)
+	assertStatusCode(t, resp, http.StatusNotFound)
+}
+
+func TestPostTask_ShouldReturnStatusConflict_WhenNameIsDuplicated(t *testing.T) {
+	server := newTestServer(t)
+	createTask(t, server, "Beautiful task.")
+
+	resp := doRequest(t, server, http.MethodPost, "/tasks", nil, map[string]interface{}{
+		"name":        "Beautiful task.",
+		"description": "A beautiful task to do.",
+		"situation":   "not started",
+	})
+	assertStatusCode(t, resp, http.StatusConflict)
+}
+
+func TestPostTask_ShouldReturnEveryFieldViolation(t *testing.T) {
+	server := newTestServer(t)
+
+	resp := doRequest(t, server, http.MethodPost, "/tasks", nil, map[string]interface{}{
+		"name":        "ab",
+		"description": "",
+		"situation":   "blocked",
+	})
+	assertStatusCode(t, resp, http.StatusBadRequest)
+
+	var body rest.RestError
+	decodeBody(t, resp, &body)
+
+	expected := []rest.Cause{
+		{Field: "description", Code: "required", Message: "description is required"},
+		{Field: "name", Code: "too_short", Message: "name must be at least 3 characters long"},
+		{Field: "situation", Code: "invalid_enum", Message: "invalid situation value"},
+	}
+	if !reflect.DeepEqual(body.Causes, expected) {
+		t.Fatalf("Expected causes %+v and received %+v", expected, body.Causes)
+	}
+}
+
+func TestGetTaskByID_ShouldReturnProblemDetails_WhenAccepted(t *testing.T) {
+	server := newTestServer(t)
+	path := "/api/v1/tasks/" + uuid.NewString()
+
+	resp := doRequest(t, server, http.MethodGet, strings.TrimPrefix(path, "/api/v1"), map[string]string{"Accept": rest.ProblemContentType}, nil)
+	assertStatusCode(t, resp, http.StatusNotFound)
+	if contentType := resp.Header.Get("Content-Type"); contentType != rest.ProblemContentType {
+		t.Fatalf("Expected Content-Type %q and received %q", rest.ProblemContentType, contentType)
+	}
+
+	var problem rest.Problem
+	decodeBody(t, resp, &problem)
+	if problem.Type != rest.ProblemTypeBaseURI+"not-found" || problem.Status != http.StatusNotFound || problem.Instance != path {
+		t.Fatalf("Unexpected problem %+v", problem)
+	}
+
+	resp = doRequest(t, server, http.MethodGet, strings.TrimPrefix(path, "/api/v1"), nil, nil)
+	if contentType := resp.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Fatalf("Expected legacy Content-Type and received %q", contentType)
+	}
+}
+
+func TestPostTask_ShouldReplayResponse_WhenIdempotencyKeyIsRepeated(t *testing.T) {
+	server := newTestServer(t)
+	headers := map[string]string{IdempotencyKeyHeader: "create-beautiful-task"}
+	payload := map[string]interface{}{
+		"name":        "Beautiful task.",
+		"description": "A beautiful task to do.",
+		"situation":   "not started",
+	}
+
+	resp := doRequest(t, server, http.MethodPost, "/tasks", headers, payload)
+	assertStatusCode(t, resp, http.StatusCreated)
+	var created TaskResponse
+	decodeBody(t, resp, &created)
+
+	resp = doRequest(t, server, http.MethodPost, "/tasks", headers, payload)
+	assertStatusCode(t, resp, http.StatusCreated)
+	if resp.Header.Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("Expected replayed response")
+	}
+	var replayed TaskResponse
+	decodeBody(t, resp, &replayed)
+	if replayed.ID != created.ID {
+		t.Fatalf("Expected task %s and received %s", created.ID, replayed.ID)
+	}
+
+	payload["name"] = "Another task."
+	resp = doRequest(t, server, http.MethodPost, "/tasks", headers, payload)
+	assertStatusCode(t, resp, http.StatusUnprocessableEntity)
+}
+
+func TestPostTask_ShouldReturnStatusConflict_WhenIdempotencyKeyIsInFlight(t *testing.T) {
+	store := idempotency.NewMemoryStore()
+	server := newTestServerWithIdempotency(t, store)
+	payload := map[string]interface{}{
+		"name":        "Beautiful task.",
+		"description": "A beautiful task to do.",
+		"situation":   "not started",
+	}
+
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", nil)
+	if _, _, err := store.Acquire(context.Background(), "in-flight", requestFingerprint(req, body), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := doRequest(t, server, http.MethodPost, "/tasks", map[string]string{IdempotencyKeyHeader: "in-flight"}, payload)
+	assertStatusCode(t, resp, http.StatusConflict)
+}
+
+func TestBatchTasks_ShouldReportPerOperationResults(t *testing.T) {
+	server := newTestServer(t)
+	existing := createTask(t, server, "Existing task.")
+
+	resp := doRequest(t, server, http.MethodPost, "/tasks:batch", nil, map[string]interface{}{
+		"operations": []map[string]interface{}{
+			{"op": "create", "name": "Batch task.", "description": "Created in a batch.", "situation": "not started"},
+			{"op": "update", "id": existing.ID, "name": "Renamed task.", "description": "Updated in a batch.", "situation": "in progress"},
+			{"op": "delete", "id": uuid.NewString()},
+			{"op": "create", "name": "", "description": "Missing name.", "situation": "not started"},
+		},
+	})
+	assertStatusCode(t, resp, http.StatusOK)
+
+	var body BatchResponse
+	decodeBody(t, resp, &body)
+	statuses := []int{}
+	for _, result := range body.Results {
+		statuses = append(statuses, result.Status)
+	}
+	expected := []int{http.StatusCreated, http.StatusOK, http.StatusNotFound, http.StatusBadRequest}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Fatalf("Expected statuses %v and received %v", expected, statuses)
+	}
+
+	resp = doRequest(t, server, http.MethodGet, "/tasks/"+existing.ID.String(), nil, nil)
+	var renamed TaskResponse
+	decodeBody(t, resp, &renamed)
+	if renamed.Name != "Renamed task." || renamed.Version != existing.Version+1 {
+		t.Fatalf("Expected cached task to reflect the batch update, received %+v", renamed)
+	}
+}
+
+func TestBatchTasks_ShouldRollBack_WhenAtomicOperationFails(t *testing.T) {
+	server := newTestServer(t)
+	existing := createTask(t, server, "Existing task.")
+
+	resp := doRequest(t, server, http.MethodPost, "/tasks:batch", nil, map[string]interface{}{
+		"atomic": true,
+		"operations": []map[string]interface{}{
+			{"op": "create", "name": "Batch task.", "description": "Created in a batch.", "situation": "not started"},
+			{"op": "create", "name": existing.Name, "description": "Duplicated name.", "situation": "not started"},
+		},
+	})
+	assertStatusCode(t, resp, http.StatusConflict)
+
+	resp = doRequest(t, server, http.MethodGet, "/tasks?name_prefix=Batch", nil, nil)
+	var page TaskPage
+	decodeBody(t, resp, &page)
+	if len(page.Data) != 0 {
+		t.Fatalf("Expected no tasks to be created, received %d", len(page.Data))
+	}
+}
+
+func TestBatchTasks_ShouldApplyOperationsInRequestOrder(t *testing.T) {
+	server := newTestServer(t)
+	existing := createTask(t, server, "Recycled task.")
+
+	resp := doRequest(t, server, http.MethodPost, "/tasks:batch", nil, map[string]interface{}{
+		"atomic": true,
+		"operations": []map[string]interface{}{
+			{"op": "delete", "id": existing.ID},
+			{"op": "create", "name": existing.Name, "description": "Replaces the deleted task.", "situation": "not started"},
+		},
+	})
+	assertStatusCode(t, resp, http.StatusOK)
+
+	resp = doRequest(t, server, http.MethodGet, "/tasks/"+existing.ID.String(), nil, nil)
+	assertStatusCode(t, resp, http.StatusNotFound)
+}
+
+func TestBatchTasks_ShouldRestoreUpdatedTask_WhenAtomicOperationFails(t *testing.T) {
+	server := newTestServer(t)
+	existing := createTask(t, server, "Existing task.")
+	other := createTask(t, server, "Other task.")
+
+	resp := doRequest(t, server, http.MethodPost, "/tasks:batch", nil, map[string]interface{}{
+		"atomic": true,
+		"operations": []map[string]interface{}{
+			{"op": "update", "id": existing.ID, "name": "Renamed task.", "description": "Updated in a batch.", "situation": "in progress"},
+			{"op": "create", "name": other.Name, "description": "Duplicated name.", "situation": "not started"},
+		},
+	})
+	assertStatusCode(t, resp, http.StatusConflict)
+
+	resp = doRequest(t, server, http.MethodGet, "/tasks/"+existing.ID.String(), nil, nil)
+	var restored TaskResponse
+	decodeBody(t, resp, &restored)
+	if restored.Name != existing.Name || restored.Version != existing.Version {
+		t.Fatalf("Expected the task to be left untouched, received %+v", restored)
+	}
+
+	resp = doRequest(t, server, http.MethodPost, "/tasks", nil, map[string]interface{}{
+		"name": "Renamed task.", "description": "Name must be free again.", "situation": "not started",
+	})
+	assertStatusCode(t, resp, http.StatusCreated)
+}
+
+func TestTransitionTask_ShouldReturnStatusConflict_WhenTransitionIsIllegal(t *testing.T) {
+	server := newTestServer(t)
+	task := createTask(t, server, "Lifecycle task.")
+
+	resp := doRequest(t, server, http.MethodPost, "/tasks/"+task.ID.String()+"/transitions", nil, map[string]interface{}{
+		"situation": "completed",
+	})
+	assertStatusCode(t, resp, http.StatusConflict)
+
+	resp = doRequest(t, server, http.MethodPost, "/tasks/"+task.ID.String()+"/transitions", nil, map[string]interface{}{
+		"situation": "in progress",
+		"reason":    "picked up",
+	})
+	assertStatusCode(t, resp, http.StatusOK)
+
+	resp = doRequest(t, server, http.MethodGet, "/tasks/"+task.ID.String()+"/transitions", nil, nil)
+	assertStatusCode(t, resp, http.StatusOK)
+
+	var transitions []TransitionResponse
+	decodeBody(t, resp, &transitions)
+	if len(transitions) != 1 || transitions[0].Reason != "picked up" {
+		t.Fatalf("Unexpected transitions %+v", transitions)
+	}
+}
+
+func TestPatchTask_ShouldOnlyChangePatchedFields(t *testing.T) {
+	server := newTestServer(t)
+	task := createTask(t, server, "Patch task.")
+
+	resp := doRequest(t, server, http.MethodPatch, "/tasks/"+task.ID.String(),
+		map[string]string{"Content-Type": "application/merge-patch+json"},
+		map[string]interface{}{"situation": "in progress"})
+	assertStatusCode(t, resp, http.StatusOK)
+
+	var patched TaskResponse
+	decodeBody(t, resp, &patched)
+	if patched.Situation != "in progress" || patched.Description != task.Description {
+		t.Fatalf("Unexpected patched task %+v", patched)
+	}
+}
+
+func TestGetTaskByID_ShouldReturnStatusNotModified_WhenETagMatches(t *testing.T) {
+	server := newTestServer(t)
+	task := createTask(t, server, "Cached task.")
+
+	resp := doRequest(t, server, http.MethodGet, "/tasks/"+task.ID.String(), map[string]string{"If-None-Match": ETag(&task)}, nil)
+	assertStatusCode(t, resp, http.StatusNotModified)
+}
+
+func TestGetAllTasks_ShouldPaginateWithCursor(t *testing.T) {
+	server := newTestServer(t)
+	for _, name := range []string{"Task A", "Task B", "Task C"} {
+		createTask(t, server, name)
+	}
+
+	var names []string
+	path := "/tasks?sort=name&limit=2"
+	for path != "" {
+		resp := doRequest(t, server, http.MethodGet, path, nil, nil)
+		assertStatusCode(t, resp, http.StatusOK)
+
+		var page TaskPage
+		decodeBody(t, resp, &page)
+		for _, task := range page.Data {
+			names = append(names, task.Name)
+		}
+
+		path = ""
+		if page.NextCursor != "" {
+			path = "/tasks?sort=name&limit=2&cursor=" + page.NextCursor
+		}
+	}
+
+	if len(names) != 3 || names[0] != "Task A" || names[2] != "Task C" {
+		t.Fatalf("Unexpected pages %v", names)
+	}
+}
+
+func TestStreamEvents_ShouldSendTaskChangesAndResumeFromLastEventID(t *testing.T) {
+	server := newTestServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	openStream := func(lastEventID string) *bufio.Reader {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/tasks/events", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		assertStatusCode(t, resp, http.StatusOK)
+		if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+			t.Fatalf("Expected text/event-stream and received %q", contentType)
+		}
+		return bufio.NewReader(resp.Body)
+	}
+	nextEvent := func(events *bufio.Reader) map[string]string {
+		fields := map[string]string{}
+		for {
+			line, err := events.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" && len(fields) > 0 {
+				return fields
+			}
+			if name, value, ok := strings.Cut(line, ": "); ok && name != "" {
+				fields[name] = value
+			}
+		}
+	}
+
+	events := openStream("")
+	task := createTask(t, server, "Streamed task.")
+	doRequest(t, server, http.MethodDelete, "/tasks/"+task.ID.String(), nil, nil)
+
+	created := nextEvent(events)
+	if created["event"] != stream.EventCreated || !strings.Contains(created["data"], task.ID.String()) {
+		t.Fatalf("Expected created event for %s and received %v", task.ID, created)
+	}
+	if deleted := nextEvent(events); deleted["event"] != stream.EventDeleted {
+		t.Fatalf("Expected deleted event and received %v", deleted)
+	}
+
+	resumed := nextEvent(openStream(created["id"]))
+	if resumed["event"] != stream.EventDeleted {
+		t.Fatalf("Expected replayed deleted event and received %v", resumed)
+	}
+}
+
+func TestServeSocket_ShouldFilterEventsAndApplyPatches(t *testing.T) {
+	server := newTestServer(t)
+	other := createTask(t, server, "Unwatched task.")
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/v1/tasks/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	roundTrip := func(req SocketRequest) SocketMessage {
+		if err := conn.WriteJSON(req); err != nil {
+			t.Fatal(err)
+		}
+		var message SocketMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			t.Fatal(err)
+		}
+		return message
+	}
+
+	if ack := roundTrip(SocketRequest{Type: SocketSubscribe, ID: "1", Situations: []domain.Situation{domain.SituationInProgress}}); ack.Type != SocketAck || ack.ID != "1" {
+		t.Fatalf("Expected subscribe ack and received %+v", ack)
+	}
+
+	doRequest(t, server, http.MethodPost, "/tasks/"+other.ID.String()+"/transitions", nil, map[string]interface{}{"situation": "blocked"})
+	task := createTask(t, server, "Watched task.")
+
+	ack := roundTrip(SocketRequest{Type: SocketPatch, ID: "2", TaskID: task.ID, Version: task.Version, Changes: json.RawMessage(`{"situation": "in progress"}`)})
+	var event SocketMessage
+	if ack.Type == SocketEvent {
+		event = ack
+		if err := conn.ReadJSON(&ack); err != nil {
+			t.Fatal(err)
+		}
+	} else if err := conn.ReadJSON(&event); err != nil {
+		t.Fatal(err)
+	}
+
+	if ack.Type != SocketAck || ack.ID != "2" || ack.Task == nil || ack.Task.Situation != domain.SituationInProgress {
+		t.Fatalf("Expected patch ack and received %+v", ack)
+	}
+	if event.Type != SocketEvent || event.Event != stream.EventUpdated || event.Task.ID != task.ID {
+		t.Fatalf("Expected update event for the watched task and received %+v", event)
+	}
+
+	stale := roundTrip(SocketRequest{Type: SocketPatch, ID: "3", TaskID: task.ID, Version: task.Version, Changes: json.RawMessage(`{"name": "Renamed task."}`)})
+	if stale.Type != SocketError || stale.Error == nil || stale.Error.Code != http.StatusPreconditionFailed {
+		t.Fatalf("Expected precondition failure for a stale version and received %+v", stale)
+	}
+}
+
+func TestServeSocket_ShouldReportTasksLeavingSubscribedSituation(t *testing.T) {
+	server := newTestServer(t)
+	moved := createTask(t, server, "Moved task.")
+	deleted := createTask(t, server, "Deleted task.")
+	for _, task := range []TaskResponse{moved, deleted} {
+		resp := doRequest(t, server, http.MethodPost, "/tasks/"+task.ID.String()+"/transitions", nil, map[string]interface{}{"situation": "in progress"})
+		assertStatusCode(t, resp, http.StatusOK)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/v1/tasks/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	read := func() SocketMessage {
+		var message SocketMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			t.Fatal(err)
+		}
+		return message
+	}
+
+	if err := conn.WriteJSON(SocketRequest{Type: SocketSubscribe, ID: "1", Situations: []domain.Situation{domain.SituationInProgress}}); err != nil {
+		t.Fatal(err)
+	}
+	if ack := read(); ack.Type != SocketAck {
+		t.Fatalf("Expected subscribe ack and received %+v", ack)
+	}
+
+	doRequest(t, server, http.MethodPost, "/tasks/"+moved.ID.String()+"/transitions", nil, map[string]interface{}{"situation": "blocked"})
+	if event := read(); event.Event != stream.EventUpdated || event.Task.ID != moved.ID || event.Task.Situation != domain.SituationBlocked {
+		t.Fatalf("Expected the task moving out of the situation to be reported and received %+v", event)
+	}
+
+	doRequest(t, server, http.MethodDelete, "/tasks/"+deleted.ID.String(), nil, nil)
+	if event := read(); event.Event != stream.EventDeleted || event.Task.ID != deleted.ID {
+		t.Fatalf("Expected the deleted task to be reported and received %+v", event)
+	}
+}
+
+func TestUpdateTask_ShouldKeepPriorityAndDueAt_WhenOmitted(t *testing.T) {
+	server := newTestServer(t)
+	dueAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+
+	resp := doRequest(t, server, http.MethodPost, "/tasks", nil, map[string]interface{}{
+		"name": "Scheduled task", "description": "A task with a deadline.", "situation": "not started",
+		"priority": "urgent", "due_at": dueAt.Format(time.RFC3339),
+	})
+	assertStatusCode(t, resp, http.StatusCreated)
+	var created TaskResponse
+	decodeBody(t, resp, &created)
+
+	resp = doRequest(t, server, http.MethodPut, "/tasks/"+created.ID.String(), nil, map[string]interface{}{
+		"name": "Scheduled task", "description": "Reworded description.", "situation": "not started",
+	})
+	assertStatusCode(t, resp, http.StatusOK)
+	var updated TaskResponse
+	decodeBody(t, resp, &updated)
+	if updated.Priority != domain.PriorityUrgent || updated.DueAt == nil || !updated.DueAt.Equal(dueAt) {
+		t.Fatalf("Expected priority and due date to be kept and received %+v", updated)
+	}
+}
+
+func TestGetAllTasks_ShouldFilterByDueDateAndSortByPriority(t *testing.T) {
+	server := newTestServer(t)
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	future := time.Now().Add(24 * time.Hour).Format(time.RFC3339)
+
+	for _, payload := range []map[string]interface{}{
+		{"name": "Late task", "situation": "not started", "priority": "high", "due_at": past},
+		{"name": "Done task", "situation": "completed", "priority": "low", "due_at": past},
+		{"name": "Next task", "situation": "in progress", "priority": "urgent", "due_at": future},
+	} {
+		payload["description"] = "A task with a deadline."
+		assertStatusCode(t, doRequest(t, server, http.MethodPost, "/tasks", nil, payload), http.StatusCreated)
+	}
+
+	resp := doRequest(t, server, http.MethodGet, "/tasks?overdue=true", nil, nil)
+	assertStatusCode(t, resp, http.StatusOK)
+	var overdue struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	decodeBody(t, resp, &overdue)
+	if len(overdue.Data) != 1 || overdue.Data[0]["name"] != "Late task" || overdue.Data[0]["overdue"] != true {
+		t.Fatalf("Expected only the late task to be overdue and received %v", overdue.Data)
+	}
+
+	resp = doRequest(t, server, http.MethodGet, "/tasks?due_before="+url.QueryEscape(time.Now().Format(time.RFC3339)), nil, nil)
+	assertStatusCode(t, resp, http.StatusOK)
+	var due TaskPage
+	decodeBody(t, resp, &due)
+	if len(due.Data) != 2 {
+		t.Fatalf("Expected 2 tasks due before now and received %d", len(due.Data))
+	}
+
+	var priorities []domain.Priority
+	path := "/tasks?sort=-priority&limit=2"
+	for path != "" {
+		resp := doRequest(t, server, http.MethodGet, path, nil, nil)
+		assertStatusCode(t, resp, http.StatusOK)
+
+		var page TaskPage
+		decodeBody(t, resp, &page)
+		for _, task := range page.Data {
+			priorities = append(priorities, task.Priority)
+		}
+
+		path = ""
+		if page.NextCursor != "" {
+			path = "/tasks?sort=-priority&limit=2&cursor=" + page.NextCursor
+		}
+	}
+
+	expected := []domain.Priority{domain.PriorityUrgent, domain.PriorityHigh, domain.PriorityLow}
+	if !reflect.DeepEqual(priorities, expected) {
+		t.Fatalf("Expected priorities %v and received %v", expected, priorities)
+	}
+}
+
+func TestPostTask_ShouldRejectUnknownPriority(t *testing.T) {
+	server := newTestServer(t)
+
+	resp := doRequest(t, server, http.MethodPost, "/tasks", nil, map[string]interface{}{
+		"name":        "Prioritised task",
+		"description": "A task with an unknown priority.",
+		"situation":   "not started",
+		"priority":    "someday",
+	})
+	assertStatusCode(t, resp, http.StatusBadRequest)
+
+	var body rest.RestError
+	decodeBody(t, resp, &body)
+	if len(body.Causes) != 1 || body.Causes[0].Field != "priority" || body.Causes[0].Code != domain.CodeInvalidEnum {
+		t.Fatalf("Expected an invalid_enum cause for priority and received %+v", body.Causes)
+	}
+}
+
+func TestGetAllTasks_ShouldFilterByAnyOrAllTags(t *testing.T) {
+	server := newTestServer(t)
+	for _, name := range []string{"backend", "billing"} {
+		assertStatusCode(t, doRequest(t, server, http.MethodPost, "/tags", nil, TagRequest{Name: name}), http.StatusCreated)
+	}
+
+	both := createTask(t, server, "Invoice API")
+	backend := createTask(t, server, "Queue worker")
+	createTask(t, server, "Landing page")
+
+	resp := doRequest(t, server, http.MethodPost, "/tasks/"+both.ID.String()+"/tags", nil, AttachTagsRequest{Tags: []string{"Billing", "backend"}})
+	assertStatusCode(t, resp, http.StatusOK)
+	var tagged TaskResponse
+	decodeBody(t, resp, &tagged)
+	if !reflect.DeepEqual(tagged.Tags, []string{"backend", "billing"}) || tagged.Version != both.Version+1 {
+		t.Fatalf("Expected normalized tags and a new version and received %+v", tagged)
+	}
+	assertStatusCode(t, doRequest(t, server, http.MethodPost, "/tasks/"+backend.ID.String()+"/tags", nil, AttachTagsRequest{Tags: []string{"backend"}}), http.StatusOK)
+
+	for path, expected := range map[string]int{
+		"/tasks?tag=backend&tag=billing":               2,
+		"/tasks?tag=backend,billing&tag_match=all":     1,
+		"/tasks?tag=billing":                           1,
+		"/tasks?tag=backend&tag=billing&tag_match=any": 2,
+	} {
+		resp := doRequest(t, server, http.MethodGet, path, nil, nil)
+		assertStatusCode(t, resp, http.StatusOK)
+		var page TaskPage
+		decodeBody(t, resp, &page)
+		if len(page.Data) != expected {
+			t.Fatalf("Expected %d tasks for %s and received %d", expected, path, len(page.Data))
+		}
+	}
+
+	assertStatusCode(t, doRequest(t, server, http.MethodGet, "/tasks?tag_match=some", nil, nil), http.StatusBadRequest)
+	assertStatusCode(t, doRequest(t, server, http.MethodPost, "/tasks/"+backend.ID.String()+"/tags", nil, AttachTagsRequest{Tags: []string{"frontend"}}), http.StatusNotFound)
+}
+
+func TestRenameTag_ShouldRefreshCachedTasks(t *testing.T) {
+	server := newTestServer(t)
+	resp := doRequest(t, server, http.MethodPost, "/tags", nil, TagRequest{Name: "backend"})
+	assertStatusCode(t, resp, http.StatusCreated)
+	var tag TagResponse
+	decodeBody(t, resp, &tag)
+	assertStatusCode(t, doRequest(t, server, http.MethodPost, "/tags", nil, TagRequest{Name: "Backend"}), http.StatusConflict)
+
+	task := createTask(t, server, "Queue worker")
+	assertStatusCode(t, doRequest(t, server, http.MethodPost, "/tasks/"+task.ID.String()+"/tags", nil, AttachTagsRequest{Tags: []string{"backend"}}), http.StatusOK)
+
+	assertStatusCode(t, doRequest(t, server, http.MethodPut, "/tags/"+tag.ID.String(), nil, TagRequest{Name: "platform"}), http.StatusOK)
+	resp = doRequest(t, server, http.MethodGet, "/tasks/"+task.ID.String(), nil, nil)
+	assertStatusCode(t, resp, http.StatusOK)
+	var renamed TaskResponse
+	decodeBody(t, resp, &renamed)
+	if !reflect.DeepEqual(renamed.Tags, []string{"platform"}) {
+		t.Fatalf("Expected the cached task to carry the renamed tag and received %v", renamed.Tags)
+	}
+
+	assertStatusCode(t, doRequest(t, server, http.MethodDelete, "/tags/"+tag.ID.String(), nil, nil), http.StatusNoContent)
+	resp = doRequest(t, server, http.MethodGet, "/tasks/"+task.ID.String(), nil, nil)
+	assertStatusCode(t, resp, http.StatusOK)
+	var untagged TaskResponse
+	decodeBody(t, resp, &untagged)
+	if len(untagged.Tags) != 0 || untagged.Version != renamed.Version+1 {
+		t.Fatalf("Expected the deleted tag to be removed with a new version and received %+v", untagged)
+	}
+	assertStatusCode(t, doRequest(t, server, http.MethodDelete, "/tasks/"+task.ID.String()+"/tags/platform", nil, nil), http.StatusNotFound)
+}
@@ -0,0 +1,155 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/felipeversiane/task-api/internal/idempotency"
+	"github.com/felipeversiane/task-api/internal/log"
+	"github.com/felipeversiane/task-api/internal/rest"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	idempotencyLockTTL       = time.Minute
+	idempotencyTTL           = 24 * time.Hour
+	maxIdempotencyKeyLength  = 255
+)
+
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (h *TaskHandler) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || h.Idempotency == nil {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			rest.RespondWithError(w, r, rest.NewBadRequestError("Idempotency-Key must have a maximum of 255 characters"))
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			rest.RespondWithError(w, r, rest.NewBadRequestError("invalid request payload"))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := r.Context()
+		fingerprint := requestFingerprint(r, body)
+		record, acquired, err := h.Idempotency.Acquire(ctx, key, fingerprint, idempotencyLockTTL)
+		if err != nil {
+			log.FromContext(ctx).Error("Failed to acquire idempotency key", slog.Any("error", err))
+			rest.RespondWithError(w, r, rest.NewServiceUnavailableError("idempotency store is unavailable"))
+			return
+		}
+
+		if !acquired {
+			switch {
+			case record.Fingerprint != fingerprint:
+				rest.RespondWithError(w, r, rest.NewUnprocessableEntityError("Idempotency-Key has already been used with a different request payload"))
+			case !record.Completed():
+				rest.RespondWithError(w, r, rest.NewConflictError("a request with this Idempotency-Key is still being processed"))
+			default:
+				replayResponse(w, record)
+			}
+			return
+		}
+
+		storeCtx := context.WithoutCancel(ctx)
+		token := record.Token
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := h.Idempotency.Release(storeCtx, key, token); err != nil {
+				log.FromContext(ctx).Error("Failed to release idempotency key", slog.Any("error", err))
+			}
+		}()
+
+		recorder := &recordingResponseWriter{ResponseWriter: w}
+		next(recorder, r)
+
+		if recorder.status == 0 || recorder.status >= http.StatusInternalServerError {
+			return
+		}
+
+		header := map[string]string{}
+		for _, name := range replayedHeaders {
+			if value := w.Header().Get(name); value != "" {
+				header[name] = value
+			}
+		}
+		result := idempotency.Record{
+			Fingerprint: fingerprint,
+			StatusCode:  recorder.status,
+			Header:      header,
+			Body:        recorder.body.Bytes(),
+		}
+		if err := h.Idempotency.Complete(storeCtx, key, token, result, idempotencyTTL); err != nil {
+			log.FromContext(ctx).Error("Failed to store idempotent response", slog.Any("error", err))
+			return
+		}
+		completed = true
+	}
+}
+
+func replayResponse(w http.ResponseWriter, record *idempotency.Record) {
+	for name, value := range record.Header {
+		w.Header().Set(name, value)
+	}
+	w.Header().Set(IdempotentReplayedHeader, strconv.FormatBool(true))
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.Body)
+}
+
+func requestFingerprint(r *http.Request, body []byte) string {
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		if canonical, err := json.Marshal(payload); err == nil {
+			body = canonical
+		}
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,587 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	domain "github.com/felipeversiane/task-api/internal"
+	"github.com/felipeversiane/task-api/internal/rest"
+	"github.com/google/uuid"
+)
+
+type MemoryStore struct {
+	mu          sync.RWMutex
+	tasks       map[uuid.UUID]TaskResponse
+	names       map[string]uuid.UUID
+	transitions map[uuid.UUID][]TransitionResponse
+	tags        map[uuid.UUID]TagResponse
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		tasks:       map[uuid.UUID]TaskResponse{},
+		names:       map[string]uuid.UUID{},
+		transitions: map[uuid.UUID][]TransitionResponse{},
+		tags:        map[uuid.UUID]TagResponse{},
+	}
+}
+
+func (m *MemoryStore) Insert(ctx context.Context, task domain.Task) (*TaskResponse, *rest.RestError) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.insert(task)
+}
+
+func (m *MemoryStore) insert(task domain.Task) (*TaskResponse, *rest.RestError) {
+	if _, ok := m.names[task.Name]; ok {
+		return nil, rest.NewConflictError(fmt.Sprintf("task with name %s already exists", task.Name))
+	}
+	if _, ok := m.tasks[task.ID]; ok {
+		return nil, rest.NewConflictError(fmt.Sprintf("task with ID %s already exists", task.ID))
+	}
+
+	stored := DomainToResponseTask(task)
+	if stored.Version == 0 {
+		stored.Version = 1
+	}
+	m.tasks[stored.ID] = stored
+	m.names[stored.Name] = stored.ID
+
+	return &stored, nil
+}
+
+func (m *MemoryStore) Update(ctx context.Context, id uuid.UUID, current *TaskResponse, task domain.Task) (*TaskResponse, *rest.RestError) {
+	return m.Patch(ctx, id, current, task, updatableFields)
+}
+
+func (m *MemoryStore) Patch(ctx context.Context, id uuid.UUID, current *TaskResponse, task domain.Task, fields []string) (*TaskResponse, *rest.RestError) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.patch(id, current, task, fields)
+}
+
+func (m *MemoryStore) patch(id uuid.UUID, current *TaskResponse, task domain.Task, fields []string) (*TaskResponse, *rest.RestError) {
+	stored, ok := m.tasks[id]
+	if !ok {
+		return nil, rest.NewNotFoundError(fmt.Sprintf("task with ID %s not found", id))
+	}
+	if stored.Version != current.Version {
+		return nil, rest.NewPreconditionFailedError(fmt.Sprintf("task with ID %s has been modified", id))
+	}
+
+	updated := stored
+	for _, field := range fields {
+		switch field {
+		case "name":
+			if existingID, ok := m.names[task.Name]; ok && existingID != id {
+				return nil, rest.NewConflictError(fmt.Sprintf("task with name %s already exists", task.Name))
+			}
+			updated.Name = task.Name
+		case "description":
+			updated.Description = task.Description
+		case "situation":
+			updated.Situation = task.Situation
+		case "priority":
+			updated.Priority = task.Priority
+		case "due_at":
+			updated.DueAt = task.DueAt
+		default:
+			return nil, rest.NewBadRequestError(fmt.Sprintf("field %s cannot be patched", field))
+		}
+	}
+	updated.UpdatedAt = task.UpdatedAt
+	updated.Version++
+
+	if stored.Situation != updated.Situation {
+		transition := domain.NewTransition(id, stored.Situation, updated.Situation, "")
+		m.transitions[id] = append(m.transitions[id], DomainToResponseTransition(transition))
+	}
+
+	delete(m.names, stored.Name)
+	m.names[updated.Name] = id
+	m.tasks[id] = updated
+
+	return &updated, nil
+}
+
+func (m *MemoryStore) Transition(ctx context.Context, current *TaskResponse, transition domain.Transition) (*TaskResponse, *rest.RestError) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.tasks[transition.TaskID]
+	if !ok {
+		return nil, rest.NewNotFoundError(fmt.Sprintf("task with ID %s not found", transition.TaskID))
+	}
+	if stored.Version != current.Version {
+		return nil, rest.NewPreconditionFailedError(fmt.Sprintf("task with ID %s has been modified", transition.TaskID))
+	}
+
+	stored.Situation = transition.To
+	stored.UpdatedAt = transition.CreatedAt
+	stored.Version++
+	m.tasks[stored.ID] = stored
+	m.transitions[stored.ID] = append(m.transitions[stored.ID], DomainToResponseTransition(transition))
+
+	return &stored, nil
+}
+
+func (m *MemoryStore) GetTransitions(ctx context.Context, id uuid.UUID) ([]TransitionResponse, *rest.RestError) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]TransitionResponse{}, m.transitions[id]...), nil
+}
+
+func (m *MemoryStore) Delete(ctx context.Context, id uuid.UUID, version int64) *rest.RestError {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.remove(id, version)
+}
+
+func (m *MemoryStore) remove(id uuid.UUID, version int64) *rest.RestError {
+	stored, ok := m.tasks[id]
+	if !ok {
+		return rest.NewNotFoundError(fmt.Sprintf("task with ID %s not found", id))
+	}
+	if stored.Version != version {
+		return rest.NewPreconditionFailedError(fmt.Sprintf("task with ID %s has been modified", id))
+	}
+
+	delete(m.tasks, id)
+	delete(m.names, stored.Name)
+	delete(m.transitions, id)
+
+	return nil
+}
+
+func (m *MemoryStore) GetByID(ctx context.Context, id uuid.UUID) (*TaskResponse, *rest.RestError) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored, ok := m.tasks[id]
+	if !ok {
+		return nil, rest.NewNotFoundError(fmt.Sprintf("task with ID %s not found", id))
+	}
+	return &stored, nil
+}
+
+func (m *MemoryStore) GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]TaskResponse, *rest.RestError) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tasks := map[uuid.UUID]TaskResponse{}
+	for _, id := range ids {
+		if stored, ok := m.tasks[id]; ok {
+			tasks[id] = stored
+		}
+	}
+	return tasks, nil
+}
+
+func (m *MemoryStore) Batch(ctx context.Context, ops []BatchOperation, atomic bool) ([]BatchResult, *rest.RestError) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var undo []func()
+	results := make([]BatchResult, 0, len(ops))
+	for _, op := range ops {
+		revert := m.snapshot(op.Task.ID)
+		var task *TaskResponse
+		var err *rest.RestError
+		switch op.Op {
+		case BatchCreate:
+			task, err = m.insert(op.Task)
+		case BatchUpdate:
+			task, err = m.patch(op.Task.ID, op.Current, op.Task, updatableFields)
+		case BatchDelete:
+			err = m.remove(op.Task.ID, op.Current.Version)
+		}
+
+		if err != nil {
+			if atomic {
+				for i := len(undo) - 1; i >= 0; i-- {
+					undo[i]()
+				}
+				return nil, batchError(op.Index, err)
+			}
+			results = append(results, batchFailure(op.Index, op.Op, err))
+			continue
+		}
+		undo = append(undo, revert)
+		results = append(results, batchSuccess(op, task))
+	}
+	return results, nil
+}
+
+// snapshot returns a function that restores the stored state of a task.
+func (m *MemoryStore) snapshot(id uuid.UUID) func() {
+	stored, existed := m.tasks[id]
+	transitions, hadTransitions := m.transitions[id]
+	transitions = slices.Clone(transitions)
+
+	return func() {
+		if current, ok := m.tasks[id]; ok {
+			delete(m.names, current.Name)
+		}
+		delete(m.tasks, id)
+		delete(m.transitions, id)
+
+		if existed {
+			m.tasks[id] = stored
+			m.names[stored.Name] = id
+		}
+		if hadTransitions {
+			m.transitions[id] = transitions
+		}
+	}
+}
+
+func (m *MemoryStore) GetAll(ctx context.Context, query TaskListQuery) ([]TaskResponse, *rest.RestError) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var cursor *TaskResponse
+	if query.Cursor != nil {
+		value, err := query.CursorValue()
+		if err != nil {
+			return nil, rest.NewBadRequestError("invalid cursor")
+		}
+		cursor = &TaskResponse{ID: query.Cursor.ID}
+		switch v := value.(type) {
+		case string:
+			cursor.Name = v
+		case int:
+			cursor.Priority, _ = domain.PriorityFromRank(v)
+		case time.Time:
+			cursor.CreatedAt, cursor.UpdatedAt = v, v
+		}
+	}
+
+	tasks := []TaskResponse{}
+	for _, task := range m.tasks {
+		if !matchesListQuery(task, query) {
+			continue
+		}
+		if cursor != nil && !query.after(task, *cursor) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return query.after(tasks[j], tasks[i])
+	})
+
+	if len(tasks) > query.Limit+1 {
+		tasks = tasks[:query.Limit+1]
+	}
+	return tasks, nil
+}
+
+func (m *MemoryStore) Search(ctx context.Context, query SearchQuery) ([]TaskSearchResult, *rest.RestError) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	positive := append(append([]string{}, query.Terms...), query.Phrases...)
+	negative := append(append([]string{}, query.ExcludedTerms...), query.ExcludedPhrases...)
+
+	results := []TaskSearchResult{}
+	for _, task := range m.tasks {
+		if len(query.Situations) > 0 && !slices.Contains(query.Situations, task.Situation) {
+			continue
+		}
+		if slices.Contains(query.ExcludedSituations, task.Situation) {
+			continue
+		}
+
+		text := strings.ToLower(task.Name + " " + task.Description)
+		rank := 0
+		matched := true
+		for _, term := range positive {
+			count := strings.Count(text, strings.ToLower(term))
+			if count == 0 {
+				matched = false
+				break
+			}
+			rank += count
+		}
+		for _, term := range negative {
+			if strings.Contains(text, strings.ToLower(term)) {
+				matched = false
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		results = append(results, TaskSearchResult{
+			Task: task,
+			Rank: float32(rank),
+			Highlights: TaskHighlights{
+				Name:        highlight(task.Name, positive),
+				Description: highlight(task.Description, positive),
+			},
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Task.ID.String() < results[j].Task.ID.String()
+	})
+
+	if len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+	return results, nil
+}
+
+func matchesListQuery(task TaskResponse, query TaskListQuery) bool {
+	if len(query.Situations) > 0 && !slices.Contains(query.Situations, task.Situation) {
+		return false
+	}
+	if query.NamePrefix != "" && !strings.HasPrefix(task.Name, query.NamePrefix) {
+		return false
+	}
+	if query.CreatedAfter != nil && task.CreatedAt.Before(*query.CreatedAfter) {
+		return false
+	}
+	if query.CreatedBefore != nil && !task.CreatedAt.Before(*query.CreatedBefore) {
+		return false
+	}
+	if query.UpdatedAfter != nil && task.UpdatedAt.Before(*query.UpdatedAfter) {
+		return false
+	}
+	if query.UpdatedBefore != nil && !task.UpdatedAt.Before(*query.UpdatedBefore) {
+		return false
+	}
+	if query.DueAfter != nil && (task.DueAt == nil || task.DueAt.Before(*query.DueAfter)) {
+		return false
+	}
+	if query.DueBefore != nil && (task.DueAt == nil || !task.DueAt.Before(*query.DueBefore)) {
+		return false
+	}
+	if query.Overdue != nil && task.IsOverdue(time.Now()) != *query.Overdue {
+		return false
+	}
+	if len(query.Tags) > 0 && !matchesTags(task.Tags, query.Tags, query.TagMatch) {
+		return false
+	}
+	return true
+}
+
+func matchesTags(attached []string, tags []string, match string) bool {
+	for _, tag := range tags {
+		found := slices.Contains(attached, tag)
+		if found && match != TagMatchAll {
+			return true
+		}
+		if !found && match == TagMatchAll {
+			return false
+		}
+	}
+	return match == TagMatchAll
+}
+
+func (q TaskListQuery) after(task TaskResponse, cursor TaskResponse) bool {
+	var cmp int
+	switch q.SortColumn {
+	case "name":
+		cmp = strings.Compare(task.Name, cursor.Name)
+	case "priority_rank":
+		cmp = domain.PriorityRank(task.Priority) - domain.PriorityRank(cursor.Priority)
+	case "updated_at":
+		cmp = task.UpdatedAt.Compare(cursor.UpdatedAt)
+	default:
+		cmp = task.CreatedAt.Compare(cursor.CreatedAt)
+	}
+	if cmp == 0 {
+		cmp = strings.Compare(task.ID.String(), cursor.ID.String())
+	}
+	if q.Descending {
+		return cmp < 0
+	}
+	return cmp > 0
+}
+
+func highlight(text string, terms []string) string {
+	text = html.EscapeString(text)
+	for _, term := range terms {
+		if term == "" {
+			continue
+		}
+		re := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(html.EscapeString(term)))
+		text = re.ReplaceAllString(text, "<mark>$0</mark>")
+	}
+	return text
+}
+
+func (m *MemoryStore) AttachTags(ctx context.Context, current *TaskResponse, names []string) (*TaskResponse, *rest.RestError) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, restErr := m.currentTask(current)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	existing := map[string]bool{}
+	for _, tag := range m.tags {
+		existing[tag.Name] = true
+	}
+	var missing []string
+	for _, name := range names {
+		if !existing[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, rest.NewNotFoundError(fmt.Sprintf("tags not found: %s", strings.Join(missing, ", ")))
+	}
+
+	stored.Tags = withTags(stored.Tags, names...)
+	stored.UpdatedAt = time.Now()
+	stored.Version++
+	m.tasks[stored.ID] = stored
+
+	return &stored, nil
+}
+
+func (m *MemoryStore) DetachTag(ctx context.Context, current *TaskResponse, name string) (*TaskResponse, *rest.RestError) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, restErr := m.currentTask(current)
+	if restErr != nil {
+		return nil, restErr
+	}
+	if !slices.Contains(stored.Tags, name) {
+		return nil, rest.NewNotFoundError(fmt.Sprintf("tag %s is not attached to task with ID %s", name, current.ID))
+	}
+
+	stored.Tags = withoutTag(stored.Tags, name)
+	stored.UpdatedAt = time.Now()
+	stored.Version++
+	m.tasks[stored.ID] = stored
+
+	return &stored, nil
+}
+
+func (m *MemoryStore) currentTask(current *TaskResponse) (TaskResponse, *rest.RestError) {
+	stored, ok := m.tasks[current.ID]
+	if !ok {
+		return TaskResponse{}, rest.NewNotFoundError(fmt.Sprintf("task with ID %s not found", current.ID))
+	}
+	if stored.Version != current.Version {
+		return TaskResponse{}, rest.NewPreconditionFailedError(fmt.Sprintf("task with ID %s has been modified", current.ID))
+	}
+	return stored, nil
+}
+
+func (m *MemoryStore) CreateTag(ctx context.Context, tag domain.Tag) (*TagResponse, *rest.RestError) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.tags {
+		if existing.Name == tag.Name {
+			return nil, rest.NewConflictError(fmt.Sprintf("tag with name %s already exists", tag.Name))
+		}
+	}
+
+	stored := DomainToResponseTag(tag)
+	m.tags[stored.ID] = stored
+
+	return &stored, nil
+}
+
+func (m *MemoryStore) GetTag(ctx context.Context, id uuid.UUID) (*TagResponse, *rest.RestError) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored, ok := m.tags[id]
+	if !ok {
+		return nil, rest.NewNotFoundError(fmt.Sprintf("tag with ID %s not found", id))
+	}
+	return &stored, nil
+}
+
+func (m *MemoryStore) GetTags(ctx context.Context) ([]TagResponse, *rest.RestError) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tags := make([]TagResponse, 0, len(m.tags))
+	for _, tag := range m.tags {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
+func (m *MemoryStore) RenameTag(ctx context.Context, id uuid.UUID, name string) (*TagResponse, []TaskChange, *rest.RestError) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.tags[id]
+	if !ok {
+		return nil, nil, rest.NewNotFoundError(fmt.Sprintf("tag with ID %s not found", id))
+	}
+	for _, existing := range m.tags {
+		if existing.Name == name && existing.ID != id {
+			return nil, nil, rest.NewConflictError(fmt.Sprintf("tag with name %s already exists", name))
+		}
+	}
+
+	previous := stored.Name
+	stored.Name = name
+	m.tags[id] = stored
+
+	changes := m.retagTasks(previous, func(tags []string) []string {
+		return withTags(withoutTag(tags, previous), name)
+	})
+	return &stored, changes, nil
+}
+
+func (m *MemoryStore) DeleteTag(ctx context.Context, id uuid.UUID) ([]TaskChange, *rest.RestError) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.tags[id]
+	if !ok {
+		return nil, rest.NewNotFoundError(fmt.Sprintf("tag with ID %s not found", id))
+	}
+	delete(m.tags, id)
+
+	changes := m.retagTasks(stored.Name, func(tags []string) []string {
+		return withoutTag(tags, stored.Name)
+	})
+	return changes, nil
+}
+
+func (m *MemoryStore) retagTasks(name string, retag func([]string) []string) []TaskChange {
+	changes := []TaskChange{}
+	for id, before := range m.tasks {
+		if !slices.Contains(before.Tags, name) {
+			continue
+		}
+		after := before
+		after.Tags = retag(before.Tags)
+		after.UpdatedAt = time.Now()
+		after.Version++
+		m.tasks[id] = after
+		changes = append(changes, TaskChange{Before: &before, After: &after})
+	}
+	return changes
+}
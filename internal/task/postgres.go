@@ -0,0 +1,1003 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	domain "github.com/felipeversiane/task-api/internal"
+	"github.com/felipeversiane/task-api/internal/database"
+	"github.com/felipeversiane/task-api/internal/outbox"
+	"github.com/felipeversiane/task-api/internal/rest"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const taskColumns = `id, name, description, situation, priority, due_at, version, created_at, updated_at`
+
+var taskFields = taskColumns + `, ` + tagNames("tasks")
+
+func tagNames(table string) string {
+	return `ARRAY(SELECT g.name FROM task_tags tt JOIN tags g ON g.id = tt.tag_id
+	              WHERE tt.task_id = ` + table + `.id ORDER BY g.name)`
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+type PostgresStore struct {
+	Database *pgxpool.Pool
+}
+
+func NewPostgresStore(database *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{
+		Database: database,
+	}
+}
+
+func (r *PostgresStore) Insert(ctx context.Context, task domain.Task) (*TaskResponse, *rest.RestError) {
+	query := `INSERT INTO tasks (` + taskColumns + `)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	          RETURNING ` + taskFields
+
+	tx, err := r.Database.Begin(ctx)
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	taskResponse, err := scanTask(tx.QueryRow(ctx, query,
+		task.ID, task.Name, task.Description, task.Situation, task.Priority, task.DueAt, task.Version, task.CreatedAt, task.UpdatedAt))
+
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, rest.NewConflictError(fmt.Sprintf("task with name %s already exists", task.Name))
+		}
+		return nil, database.MapError(err)
+	}
+
+	if err := writeTaskEvent(ctx, tx, outbox.TaskCreated, nil, &taskResponse); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, database.MapError(err)
+	}
+
+	return &taskResponse, nil
+}
+
+func (r *PostgresStore) Update(ctx context.Context, id uuid.UUID, current *TaskResponse, task domain.Task) (*TaskResponse, *rest.RestError) {
+	return r.Patch(ctx, id, current, task, updatableFields)
+}
+
+func (r *PostgresStore) Patch(ctx context.Context, id uuid.UUID, current *TaskResponse, task domain.Task, fields []string) (*TaskResponse, *rest.RestError) {
+	columns := map[string]interface{}{
+		"name":        task.Name,
+		"description": task.Description,
+		"situation":   task.Situation,
+		"priority":    task.Priority,
+		"due_at":      task.DueAt,
+	}
+
+	var assignments []string
+	var args []interface{}
+	for _, field := range fields {
+		value, ok := columns[field]
+		if !ok {
+			return nil, rest.NewBadRequestError(fmt.Sprintf("field %s cannot be patched", field))
+		}
+		args = append(args, value)
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", field, len(args)))
+	}
+	args = append(args, task.UpdatedAt)
+	assignments = append(assignments, fmt.Sprintf("updated_at = $%d", len(args)), "version = version + 1")
+
+	args = append(args, id, current.Version)
+	query := fmt.Sprintf(`UPDATE tasks SET %s
+	          WHERE id = $%d AND version = $%d
+	          RETURNING %s`,
+		strings.Join(assignments, ", "), len(args)-1, len(args), taskFields)
+
+	tx, err := r.Database.Begin(ctx)
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	taskResponse, err := scanTask(tx.QueryRow(ctx, query, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, rest.NewPreconditionFailedError(fmt.Sprintf("task with ID %s has been modified", id))
+		}
+		if database.IsUniqueViolation(err) {
+			return nil, rest.NewConflictError(fmt.Sprintf("task with name %s already exists", task.Name))
+		}
+		return nil, database.MapError(err)
+	}
+
+	if current.Situation != taskResponse.Situation {
+		transition := domain.NewTransition(id, current.Situation, taskResponse.Situation, "")
+		if err := insertTransition(ctx, tx, transition); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writeTaskEvent(ctx, tx, outbox.TaskUpdated, current, &taskResponse); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, database.MapError(err)
+	}
+
+	return &taskResponse, nil
+}
+
+func (r *PostgresStore) Transition(ctx context.Context, current *TaskResponse, transition domain.Transition) (*TaskResponse, *rest.RestError) {
+	tx, err := r.Database.Begin(ctx)
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE tasks SET situation = $1, updated_at = $2, version = version + 1
+	          WHERE id = $3 AND version = $4
+	          RETURNING ` + taskFields
+
+	taskResponse, err := scanTask(tx.QueryRow(ctx, query, transition.To, transition.CreatedAt, transition.TaskID, current.Version))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, rest.NewPreconditionFailedError(fmt.Sprintf("task with ID %s has been modified", transition.TaskID))
+		}
+		return nil, database.MapError(err)
+	}
+
+	if err := insertTransition(ctx, tx, transition); err != nil {
+		return nil, err
+	}
+
+	if err := writeTaskEvent(ctx, tx, outbox.TaskUpdated, current, &taskResponse); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, database.MapError(err)
+	}
+
+	return &taskResponse, nil
+}
+
+func (r *PostgresStore) GetTransitions(ctx context.Context, id uuid.UUID) ([]TransitionResponse, *rest.RestError) {
+	query := `SELECT id, task_id, from_situation, to_situation, COALESCE(reason, ''), created_at
+	          FROM task_transitions WHERE task_id = $1 ORDER BY created_at`
+
+	rows, err := r.Database.Query(ctx, query, id)
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	defer rows.Close()
+
+	transitions := []TransitionResponse{}
+	for rows.Next() {
+		var transition TransitionResponse
+		if err := rows.Scan(&transition.ID, &transition.TaskID, &transition.From, &transition.To,
+			&transition.Reason, &transition.CreatedAt); err != nil {
+			return nil, database.MapError(err)
+		}
+		transitions = append(transitions, transition)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, database.MapError(err)
+	}
+
+	return transitions, nil
+}
+
+func insertTransition(ctx context.Context, tx pgx.Tx, transition domain.Transition) *rest.RestError {
+	query := `INSERT INTO task_transitions (id, task_id, from_situation, to_situation, reason, created_at)
+	          VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)`
+
+	_, err := tx.Exec(ctx, query, transition.ID, transition.TaskID, transition.From, transition.To,
+		transition.Reason, transition.CreatedAt)
+	if err != nil {
+		return database.MapError(err)
+	}
+	return nil
+}
+
+func (r *PostgresStore) Delete(ctx context.Context, id uuid.UUID, version int64) *rest.RestError {
+	tx, err := r.Database.Begin(ctx)
+	if err != nil {
+		return database.MapError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `DELETE FROM tasks WHERE id = $1 AND version = $2 RETURNING ` + taskFields
+	deleted, err := scanTask(tx.QueryRow(ctx, query, id, version))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return rest.NewPreconditionFailedError(fmt.Sprintf("task with ID %s has been modified", id))
+		}
+		return database.MapError(err)
+	}
+
+	if err := writeTaskEvent(ctx, tx, outbox.TaskDeleted, &deleted, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return database.MapError(err)
+	}
+
+	return nil
+}
+
+func (r *PostgresStore) GetByID(ctx context.Context, id uuid.UUID) (*TaskResponse, *rest.RestError) {
+	query := `SELECT ` + taskFields + ` FROM tasks WHERE id = $1`
+	task, err := scanTask(r.Database.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, rest.NewNotFoundError(fmt.Sprintf("task with ID %s not found", id))
+		}
+		return nil, database.MapError(err)
+	}
+
+	return &task, nil
+}
+
+func (r *PostgresStore) GetAll(ctx context.Context, query TaskListQuery) ([]TaskResponse, *rest.RestError) {
+	var conditions []string
+	var args []interface{}
+	addCondition := func(format string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if len(query.Situations) > 0 {
+		addCondition("situation = ANY($%d)", situationStrings(query.Situations))
+	}
+	if query.NamePrefix != "" {
+		addCondition(`name LIKE $%d || '%%'`, escapeLike(query.NamePrefix))
+	}
+	if query.CreatedAfter != nil {
+		addCondition("created_at >= $%d", *query.CreatedAfter)
+	}
+	if query.CreatedBefore != nil {
+		addCondition("created_at < $%d", *query.CreatedBefore)
+	}
+	if query.UpdatedAfter != nil {
+		addCondition("updated_at >= $%d", *query.UpdatedAfter)
+	}
+	if query.UpdatedBefore != nil {
+		addCondition("updated_at < $%d", *query.UpdatedBefore)
+	}
+	if query.DueAfter != nil {
+		addCondition("due_at >= $%d", *query.DueAfter)
+	}
+	if query.DueBefore != nil {
+		addCondition("due_at < $%d", *query.DueBefore)
+	}
+	if query.Overdue != nil {
+		args = append(args, time.Now(), situationStrings(domain.ClosedSituations()))
+		overdue := fmt.Sprintf("(due_at < $%d AND situation <> ALL($%d))", len(args)-1, len(args))
+		if !*query.Overdue {
+			overdue = "NOT COALESCE(" + overdue + ", false)"
+		}
+		conditions = append(conditions, overdue)
+	}
+	if len(query.Tags) > 0 {
+		tagged := `SELECT %s FROM task_tags tt JOIN tags g ON g.id = tt.tag_id
+		           WHERE tt.task_id = tasks.id AND g.name = ANY($%%d)`
+		if query.TagMatch == TagMatchAll {
+			addCondition(fmt.Sprintf("("+tagged+") = %d", "COUNT(*)", len(query.Tags)), query.Tags)
+		} else {
+			addCondition(fmt.Sprintf("EXISTS ("+tagged+")", "1"), query.Tags)
+		}
+	}
+
+	direction, comparison := "ASC", ">"
+	if query.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if query.Cursor != nil {
+		value, err := query.CursorValue()
+		if err != nil {
+			return nil, rest.NewBadRequestError("invalid cursor")
+		}
+		args = append(args, value, query.Cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d, $%d)",
+			query.SortColumn, comparison, len(args)-1, len(args)))
+	}
+
+	sql := `SELECT ` + taskFields + ` FROM tasks`
+	if len(conditions) > 0 {
+		sql += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	args = append(args, query.Limit+1)
+	sql += fmt.Sprintf(` ORDER BY %s %s, id %s LIMIT $%d`, query.SortColumn, direction, direction, len(args))
+
+	rows, err := r.Database.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	defer rows.Close()
+
+	tasks := []TaskResponse{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, database.MapError(err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, database.MapError(err)
+	}
+
+	return tasks, nil
+}
+
+func (r *PostgresStore) Search(ctx context.Context, query SearchQuery) ([]TaskSearchResult, *rest.RestError) {
+	var args []interface{}
+	var parts []string
+	addPart := func(format string, value string) {
+		args = append(args, value)
+		parts = append(parts, fmt.Sprintf(format, len(args)))
+	}
+
+	for _, term := range query.Terms {
+		addPart("plainto_tsquery('english', $%d)", term)
+	}
+	for _, phrase := range query.Phrases {
+		addPart("phraseto_tsquery('english', $%d)", phrase)
+	}
+	for _, term := range query.ExcludedTerms {
+		addPart("!!plainto_tsquery('english', $%d)", term)
+	}
+	for _, phrase := range query.ExcludedPhrases {
+		addPart("!!phraseto_tsquery('english', $%d)", phrase)
+	}
+
+	conditions := []string{"t.search @@ q.query"}
+	if len(query.Situations) > 0 {
+		args = append(args, situationStrings(query.Situations))
+		conditions = append(conditions, fmt.Sprintf("t.situation = ANY($%d)", len(args)))
+	}
+	if len(query.ExcludedSituations) > 0 {
+		args = append(args, situationStrings(query.ExcludedSituations))
+		conditions = append(conditions, fmt.Sprintf("t.situation <> ALL($%d)", len(args)))
+	}
+	args = append(args, query.Limit)
+
+	sql := fmt.Sprintf(`WITH q AS (SELECT %s AS query)
+	          SELECT t.id, t.name, t.description, t.situation, t.priority, t.due_at, t.version, t.created_at, t.updated_at,
+	                 `+tagNames("t")+`,
+	                 ts_rank(t.search, q.query) AS rank,
+	                 ts_headline('english', `+escapeHTML("t.name")+`, q.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+	                 ts_headline('english', `+escapeHTML("COALESCE(t.description, '')")+`, q.query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2')
+	          FROM tasks t, q
+	          WHERE %s
+	          ORDER BY rank DESC, t.id
+	          LIMIT $%d`,
+		strings.Join(parts, " && "), strings.Join(conditions, " AND "), len(args))
+
+	rows, err := r.Database.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	defer rows.Close()
+
+	results := []TaskSearchResult{}
+	for rows.Next() {
+		var result TaskSearchResult
+		task := &result.Task
+		if err := rows.Scan(&task.ID, &task.Name, &task.Description, &task.Situation, &task.Priority, &task.DueAt,
+			&task.Version, &task.CreatedAt, &task.UpdatedAt, &task.Tags, &result.Rank,
+			&result.Highlights.Name, &result.Highlights.Description); err != nil {
+			return nil, database.MapError(err)
+		}
+		task.DueAt = utcDueAt(task.DueAt)
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, database.MapError(err)
+	}
+
+	return results, nil
+}
+
+func situationStrings(situations []domain.Situation) []string {
+	values := make([]string, len(situations))
+	for i, situation := range situations {
+		values[i] = string(situation)
+	}
+	return values
+}
+
+// escapeHTML escapes a column the way html.EscapeString does.
+func escapeHTML(column string) string {
+	return `replace(replace(replace(replace(replace(` + column + `,
+	        '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'), '''', '&#39;')`
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func scanTask(row pgx.Row) (TaskResponse, error) {
+	var task TaskResponse
+	err := row.Scan(&task.ID, &task.Name, &task.Description, &task.Situation, &task.Priority, &task.DueAt,
+		&task.Version, &task.CreatedAt, &task.UpdatedAt, &task.Tags)
+	task.DueAt = utcDueAt(task.DueAt)
+	if task.Tags == nil {
+		task.Tags = []string{}
+	}
+	return task, err
+}
+
+func utcDueAt(dueAt *time.Time) *time.Time {
+	if dueAt == nil {
+		return nil
+	}
+	utc := dueAt.UTC()
+	return &utc
+}
+
+func (r *PostgresStore) GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]TaskResponse, *rest.RestError) {
+	if len(ids) == 0 {
+		return map[uuid.UUID]TaskResponse{}, nil
+	}
+	return queryTasksByIDs(ctx, r.Database, ids)
+}
+
+func queryTasksByIDs(ctx context.Context, db querier, ids []uuid.UUID) (map[uuid.UUID]TaskResponse, *rest.RestError) {
+	rows, err := db.Query(ctx, `SELECT `+taskFields+` FROM tasks WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	defer rows.Close()
+
+	tasks := map[uuid.UUID]TaskResponse{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, database.MapError(err)
+		}
+		tasks[task.ID] = task
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, database.MapError(err)
+	}
+
+	return tasks, nil
+}
+
+func (r *PostgresStore) Batch(ctx context.Context, ops []BatchOperation, atomic bool) ([]BatchResult, *rest.RestError) {
+	tx, err := r.Database.Begin(ctx)
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	var results []BatchResult
+	var events []outbox.Event
+	var restErr *rest.RestError
+	if atomic {
+		results, restErr = batchAtomic(ctx, tx, ops, &events)
+	} else {
+		results, restErr = batchEach(ctx, tx, ops, &events)
+	}
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if err := outbox.Write(ctx, tx, events...); err != nil {
+		return nil, database.MapError(err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, database.MapError(err)
+	}
+
+	return results, nil
+}
+
+func batchAtomic(ctx context.Context, tx pgx.Tx, ops []BatchOperation, events *[]outbox.Event) ([]BatchResult, *rest.RestError) {
+	if onlyCreates(ops) {
+		results, copied, err := batchCopy(ctx, tx, ops, events)
+		if copied || err != nil {
+			return results, err
+		}
+	}
+
+	batch := &pgx.Batch{}
+	for _, op := range ops {
+		query, args := batchStatement(op)
+		batch.Queue(query, args...)
+	}
+
+	batchResults := tx.SendBatch(ctx, batch)
+	defer batchResults.Close()
+
+	results := make([]BatchResult, 0, len(ops))
+	for _, op := range ops {
+		task, err := scanTask(batchResults.QueryRow())
+		if err != nil {
+			return nil, batchError(op.Index, batchStatementError(op, err))
+		}
+		if err := appendTaskEvent(events, op, &task); err != nil {
+			return nil, err
+		}
+		results = append(results, batchSuccess(op, &task))
+	}
+
+	if err := batchResults.Close(); err != nil {
+		return nil, database.MapError(err)
+	}
+	return results, nil
+}
+
+func onlyCreates(ops []BatchOperation) bool {
+	for _, op := range ops {
+		if op.Op != BatchCreate {
+			return false
+		}
+	}
+	return true
+}
+
+// batchCopy reports copied as false on a conflict so the statement batch can name the failing operation.
+func batchCopy(ctx context.Context, tx pgx.Tx, ops []BatchOperation, events *[]outbox.Event) ([]BatchResult, bool, *rest.RestError) {
+	if _, err := tx.Exec(ctx, `SAVEPOINT batch_copy`); err != nil {
+		return nil, false, database.MapError(err)
+	}
+
+	rows := make([][]interface{}, len(ops))
+	ids := make([]uuid.UUID, len(ops))
+	for i, op := range ops {
+		task := op.Task
+		rows[i] = []interface{}{task.ID, task.Name, task.Description, task.Situation, task.Priority, task.DueAt,
+			task.Version, task.CreatedAt, task.UpdatedAt}
+		ids[i] = task.ID
+	}
+
+	_, err := tx.CopyFrom(ctx, pgx.Identifier{"tasks"}, strings.Split(taskColumns, ", "), pgx.CopyFromRows(rows))
+	if database.IsUniqueViolation(err) {
+		if _, err := tx.Exec(ctx, `ROLLBACK TO SAVEPOINT batch_copy`); err != nil {
+			return nil, false, database.MapError(err)
+		}
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, database.MapError(err)
+	}
+
+	stored, restErr := queryTasksByIDs(ctx, tx, ids)
+	if restErr != nil {
+		return nil, false, restErr
+	}
+
+	results := make([]BatchResult, 0, len(ops))
+	for _, op := range ops {
+		task := stored[op.Task.ID]
+		if err := appendTaskEvent(events, op, &task); err != nil {
+			return nil, false, err
+		}
+		results = append(results, batchSuccess(op, &task))
+	}
+	return results, true, nil
+}
+
+// batchEach resends the operations after a failed statement, which aborts the rest of the pipeline.
+func batchEach(ctx context.Context, tx pgx.Tx, ops []BatchOperation, events *[]outbox.Event) ([]BatchResult, *rest.RestError) {
+	results := make([]BatchResult, 0, len(ops))
+	for len(results) < len(ops) {
+		sent, err := sendBatchEach(ctx, tx, ops[len(results):], events)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, sent...)
+	}
+	return results, nil
+}
+
+func sendBatchEach(ctx context.Context, tx pgx.Tx, ops []BatchOperation, events *[]outbox.Event) ([]BatchResult, *rest.RestError) {
+	batch := &pgx.Batch{}
+	for _, op := range ops {
+		query, args := batchStatement(op)
+		batch.Queue(`SAVEPOINT batch_operation`)
+		batch.Queue(query, args...)
+		batch.Queue(`RELEASE SAVEPOINT batch_operation`)
+	}
+
+	batchResults := tx.SendBatch(ctx, batch)
+	defer batchResults.Close()
+
+	results := make([]BatchResult, 0, len(ops))
+	for _, op := range ops {
+		if _, err := batchResults.Exec(); err != nil {
+			return nil, database.MapError(err)
+		}
+
+		task, err := scanTask(batchResults.QueryRow())
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			batchResults.Close()
+			if _, rollbackErr := tx.Exec(ctx, `ROLLBACK TO SAVEPOINT batch_operation`); rollbackErr != nil {
+				return nil, database.MapError(rollbackErr)
+			}
+			return append(results, batchFailure(op.Index, op.Op, batchStatementError(op, err))), nil
+		}
+
+		if _, releaseErr := batchResults.Exec(); releaseErr != nil {
+			return nil, database.MapError(releaseErr)
+		}
+		if err != nil {
+			results = append(results, batchFailure(op.Index, op.Op, batchMissError(op)))
+			continue
+		}
+		if err := appendTaskEvent(events, op, &task); err != nil {
+			return nil, err
+		}
+		results = append(results, batchSuccess(op, &task))
+	}
+
+	if err := batchResults.Close(); err != nil {
+		return nil, database.MapError(err)
+	}
+	return results, nil
+}
+
+func batchStatement(op BatchOperation) (string, []interface{}) {
+	task := op.Task
+	switch op.Op {
+	case BatchCreate:
+		return `INSERT INTO tasks (` + taskColumns + `)
+		        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		        ON CONFLICT DO NOTHING
+		        RETURNING ` + taskFields,
+			[]interface{}{task.ID, task.Name, task.Description, task.Situation, task.Priority, task.DueAt,
+				task.Version, task.CreatedAt, task.UpdatedAt}
+	case BatchUpdate:
+		return `WITH updated AS (
+		            UPDATE tasks SET name = $1, description = $2, situation = $3, priority = $4, due_at = $5,
+		                             updated_at = $6, version = version + 1
+		            WHERE id = $7 AND version = $8
+		            RETURNING ` + taskColumns + `
+		        ), transition AS (
+		            INSERT INTO task_transitions (id, task_id, from_situation, to_situation, created_at)
+		            SELECT $9, id, $10, situation, updated_at FROM updated WHERE situation <> $10
+		        )
+		        SELECT ` + taskColumns + `, ` + tagNames("updated") + ` FROM updated`,
+			[]interface{}{task.Name, task.Description, task.Situation, task.Priority, task.DueAt, task.UpdatedAt,
+				task.ID, op.Current.Version, uuid.New(), op.Current.Situation}
+	default:
+		return `DELETE FROM tasks WHERE id = $1 AND version = $2 RETURNING ` + taskFields,
+			[]interface{}{task.ID, op.Current.Version}
+	}
+}
+
+func batchStatementError(op BatchOperation, err error) *rest.RestError {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return batchMissError(op)
+	}
+	if database.IsUniqueViolation(err) {
+		return rest.NewConflictError(fmt.Sprintf("task with name %s already exists", op.Task.Name))
+	}
+	return database.MapError(err)
+}
+
+func appendTaskEvent(events *[]outbox.Event, op BatchOperation, task *TaskResponse) *rest.RestError {
+	var event outbox.Event
+	var err *rest.RestError
+	switch op.Op {
+	case BatchCreate:
+		event, err = newTaskEvent(outbox.TaskCreated, nil, task)
+	case BatchUpdate:
+		event, err = newTaskEvent(outbox.TaskUpdated, op.Current, task)
+	case BatchDelete:
+		event, err = newTaskEvent(outbox.TaskDeleted, task, nil)
+	}
+	if err != nil {
+		return err
+	}
+	*events = append(*events, event)
+	return nil
+}
+
+func (r *PostgresStore) AttachTags(ctx context.Context, current *TaskResponse, names []string) (*TaskResponse, *rest.RestError) {
+	tx, err := r.Database.Begin(ctx)
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := touchTask(ctx, tx, current); err != nil {
+		return nil, err
+	}
+
+	query := `INSERT INTO task_tags (task_id, tag_id)
+	          SELECT $1, id FROM tags WHERE name = ANY($2)
+	          ON CONFLICT DO NOTHING`
+	if _, err := tx.Exec(ctx, query, current.ID, names); err != nil {
+		return nil, database.MapError(err)
+	}
+
+	taskResponse, err := scanTask(tx.QueryRow(ctx, `SELECT `+taskFields+` FROM tasks WHERE id = $1`, current.ID))
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	if missing := missingTags(taskResponse.Tags, names); len(missing) > 0 {
+		return nil, rest.NewNotFoundError(fmt.Sprintf("tags not found: %s", strings.Join(missing, ", ")))
+	}
+
+	if err := writeTaskEvent(ctx, tx, outbox.TaskUpdated, current, &taskResponse); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, database.MapError(err)
+	}
+
+	return &taskResponse, nil
+}
+
+func (r *PostgresStore) DetachTag(ctx context.Context, current *TaskResponse, name string) (*TaskResponse, *rest.RestError) {
+	tx, err := r.Database.Begin(ctx)
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := touchTask(ctx, tx, current); err != nil {
+		return nil, err
+	}
+
+	query := `DELETE FROM task_tags tt USING tags g
+	          WHERE g.id = tt.tag_id AND tt.task_id = $1 AND g.name = $2`
+	result, err := tx.Exec(ctx, query, current.ID, name)
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, rest.NewNotFoundError(fmt.Sprintf("tag %s is not attached to task with ID %s", name, current.ID))
+	}
+
+	taskResponse, err := scanTask(tx.QueryRow(ctx, `SELECT `+taskFields+` FROM tasks WHERE id = $1`, current.ID))
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+
+	if err := writeTaskEvent(ctx, tx, outbox.TaskUpdated, current, &taskResponse); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, database.MapError(err)
+	}
+
+	return &taskResponse, nil
+}
+
+func touchTask(ctx context.Context, tx pgx.Tx, current *TaskResponse) *rest.RestError {
+	query := `UPDATE tasks SET updated_at = $1, version = version + 1 WHERE id = $2 AND version = $3`
+	result, err := tx.Exec(ctx, query, time.Now(), current.ID, current.Version)
+	if err != nil {
+		return database.MapError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return rest.NewPreconditionFailedError(fmt.Sprintf("task with ID %s has been modified", current.ID))
+	}
+	return nil
+}
+
+func (r *PostgresStore) CreateTag(ctx context.Context, tag domain.Tag) (*TagResponse, *rest.RestError) {
+	query := `INSERT INTO tags (id, name, created_at) VALUES ($1, $2, $3) RETURNING id, name, created_at`
+
+	tagResponse, err := scanTag(r.Database.QueryRow(ctx, query, tag.ID, tag.Name, tag.CreatedAt))
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, rest.NewConflictError(fmt.Sprintf("tag with name %s already exists", tag.Name))
+		}
+		return nil, database.MapError(err)
+	}
+
+	return &tagResponse, nil
+}
+
+func (r *PostgresStore) GetTag(ctx context.Context, id uuid.UUID) (*TagResponse, *rest.RestError) {
+	tag, err := scanTag(r.Database.QueryRow(ctx, `SELECT id, name, created_at FROM tags WHERE id = $1`, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, rest.NewNotFoundError(fmt.Sprintf("tag with ID %s not found", id))
+		}
+		return nil, database.MapError(err)
+	}
+
+	return &tag, nil
+}
+
+func (r *PostgresStore) GetTags(ctx context.Context) ([]TagResponse, *rest.RestError) {
+	rows, err := r.Database.Query(ctx, `SELECT id, name, created_at FROM tags ORDER BY name`)
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	defer rows.Close()
+
+	tags := []TagResponse{}
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			return nil, database.MapError(err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, database.MapError(err)
+	}
+
+	return tags, nil
+}
+
+func (r *PostgresStore) RenameTag(ctx context.Context, id uuid.UUID, name string) (*TagResponse, []TaskChange, *rest.RestError) {
+	tx, err := r.Database.Begin(ctx)
+	if err != nil {
+		return nil, nil, database.MapError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, restErr := lockTaggedTasks(ctx, tx, id)
+	if restErr != nil {
+		return nil, nil, restErr
+	}
+
+	tag, err := scanTag(tx.QueryRow(ctx, `UPDATE tags SET name = $1 WHERE id = $2 RETURNING id, name, created_at`, name, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil, rest.NewNotFoundError(fmt.Sprintf("tag with ID %s not found", id))
+		}
+		if database.IsUniqueViolation(err) {
+			return nil, nil, rest.NewConflictError(fmt.Sprintf("tag with name %s already exists", name))
+		}
+		return nil, nil, database.MapError(err)
+	}
+
+	changes, restErr := touchTaggedTasks(ctx, tx, before)
+	if restErr != nil {
+		return nil, nil, restErr
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, database.MapError(err)
+	}
+
+	return &tag, changes, nil
+}
+
+func (r *PostgresStore) DeleteTag(ctx context.Context, id uuid.UUID) ([]TaskChange, *rest.RestError) {
+	tx, err := r.Database.Begin(ctx)
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, restErr := lockTaggedTasks(ctx, tx, id)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM tags WHERE id = $1`, id)
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, rest.NewNotFoundError(fmt.Sprintf("tag with ID %s not found", id))
+	}
+
+	changes, restErr := touchTaggedTasks(ctx, tx, before)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, database.MapError(err)
+	}
+
+	return changes, nil
+}
+
+func lockTaggedTasks(ctx context.Context, tx pgx.Tx, tagID uuid.UUID) (map[uuid.UUID]TaskResponse, *rest.RestError) {
+	query := `SELECT ` + taskFields + ` FROM tasks
+	          WHERE id IN (SELECT task_id FROM task_tags WHERE tag_id = $1)
+	          FOR UPDATE`
+
+	rows, err := tx.Query(ctx, query, tagID)
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	defer rows.Close()
+
+	tasks := map[uuid.UUID]TaskResponse{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, database.MapError(err)
+		}
+		tasks[task.ID] = task
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, database.MapError(err)
+	}
+
+	return tasks, nil
+}
+
+func touchTaggedTasks(ctx context.Context, tx pgx.Tx, before map[uuid.UUID]TaskResponse) ([]TaskChange, *rest.RestError) {
+	changes := make([]TaskChange, 0, len(before))
+	if len(before) == 0 {
+		return changes, nil
+	}
+
+	query := `UPDATE tasks SET updated_at = $1, version = version + 1
+	          WHERE id = ANY($2)
+	          RETURNING ` + taskFields
+
+	ids := make([]uuid.UUID, 0, len(before))
+	for id := range before {
+		ids = append(ids, id)
+	}
+
+	rows, err := tx.Query(ctx, query, time.Now(), ids)
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			rows.Close()
+			return nil, database.MapError(err)
+		}
+		previous := before[task.ID]
+		changes = append(changes, TaskChange{Before: &previous, After: &task})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, database.MapError(err)
+	}
+
+	for _, change := range changes {
+		if err := writeTaskEvent(ctx, tx, outbox.TaskUpdated, change.Before, change.After); err != nil {
+			return nil, err
+		}
+	}
+
+	return changes, nil
+}
+
+func missingTags(attached []string, names []string) []string {
+	var missing []string
+	for _, name := range names {
+		if !slices.Contains(attached, name) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+func scanTag(row pgx.Row) (TagResponse, error) {
+	var tag TagResponse
+	err := row.Scan(&tag.ID, &tag.Name, &tag.CreatedAt)
+	return tag, err
+}
@@ -0,0 +1,207 @@
+package task
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "github.com/felipeversiane/task-api/internal"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+	defaultListSort  = "-created_at"
+)
+
+var sortColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"name":       "name",
+	"priority":   "priority_rank",
+}
+
+type TaskListQuery struct {
+	Limit         int
+	Cursor        *TaskCursor
+	Sort          string
+	SortColumn    string
+	Descending    bool
+	Situations    []domain.Situation
+	NamePrefix    string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	DueAfter      *time.Time
+	DueBefore     *time.Time
+	Overdue       *bool
+	Tags          []string
+	TagMatch      string
+}
+
+type TaskCursor struct {
+	Sort  string    `json:"s"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+type TaskPage struct {
+	Data       []TaskResponse `json:"data"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+func ParseTaskListQuery(values url.Values) (TaskListQuery, error) {
+	query := TaskListQuery{
+		Limit: defaultListLimit,
+		Sort:  defaultListSort,
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxListLimit {
+			return query, fmt.Errorf("limit must be an integer between 1 and %d", maxListLimit)
+		}
+		query.Limit = n
+	}
+
+	if sort := values.Get("sort"); sort != "" {
+		query.Sort = sort
+	}
+	query.Descending = strings.HasPrefix(query.Sort, "-")
+	column, ok := sortColumns[strings.TrimPrefix(query.Sort, "-")]
+	if !ok {
+		return query, fmt.Errorf("invalid sort value %q", query.Sort)
+	}
+	query.SortColumn = column
+
+	for _, value := range values["situation"] {
+		for _, situation := range strings.Split(value, ",") {
+			s := domain.Situation(strings.TrimSpace(situation))
+			if !domain.IsValidSituation(s) {
+				return query, fmt.Errorf("invalid situation value %q", s)
+			}
+			query.Situations = append(query.Situations, s)
+		}
+	}
+
+	query.NamePrefix = values.Get("name_prefix")
+
+	var err error
+	if query.CreatedAfter, err = parseTimeParam(values, "created_after"); err != nil {
+		return query, err
+	}
+	if query.CreatedBefore, err = parseTimeParam(values, "created_before"); err != nil {
+		return query, err
+	}
+	if query.UpdatedAfter, err = parseTimeParam(values, "updated_after"); err != nil {
+		return query, err
+	}
+	if query.UpdatedBefore, err = parseTimeParam(values, "updated_before"); err != nil {
+		return query, err
+	}
+	if query.DueAfter, err = parseTimeParam(values, "due_after"); err != nil {
+		return query, err
+	}
+	if query.DueBefore, err = parseTimeParam(values, "due_before"); err != nil {
+		return query, err
+	}
+
+	for _, value := range values["tag"] {
+		for _, name := range strings.Split(value, ",") {
+			name = domain.NormalizeTagName(name)
+			if err := domain.ValidateTagName("tag", name); err != nil {
+				return query, fmt.Errorf("invalid tag value %q", name)
+			}
+			if !slices.Contains(query.Tags, name) {
+				query.Tags = append(query.Tags, name)
+			}
+		}
+	}
+
+	query.TagMatch = TagMatchAny
+	if match := values.Get("tag_match"); match != "" {
+		if match != TagMatchAny && match != TagMatchAll {
+			return query, fmt.Errorf("tag_match must be %s or %s", TagMatchAny, TagMatchAll)
+		}
+		query.TagMatch = match
+	}
+
+	if overdue := values.Get("overdue"); overdue != "" {
+		value, err := strconv.ParseBool(overdue)
+		if err != nil {
+			return query, fmt.Errorf("overdue must be true or false")
+		}
+		query.Overdue = &value
+	}
+
+	if cursor := values.Get("cursor"); cursor != "" {
+		c, err := DecodeCursor(cursor)
+		if err != nil || c.Sort != query.Sort {
+			return query, fmt.Errorf("invalid cursor")
+		}
+		query.Cursor = c
+	}
+
+	return query, nil
+}
+
+func parseTimeParam(values url.Values, name string) (*time.Time, error) {
+	value := values.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+	}
+	return &t, nil
+}
+
+func (q TaskListQuery) CursorValue() (interface{}, error) {
+	switch q.SortColumn {
+	case "name":
+		return q.Cursor.Value, nil
+	case "priority_rank":
+		return strconv.Atoi(q.Cursor.Value)
+	}
+	return time.Parse(time.RFC3339Nano, q.Cursor.Value)
+}
+
+func (q TaskListQuery) NextCursor(task TaskResponse) string {
+	cursor := TaskCursor{Sort: q.Sort, ID: task.ID}
+	switch q.SortColumn {
+	case "name":
+		cursor.Value = task.Name
+	case "priority_rank":
+		cursor.Value = strconv.Itoa(domain.PriorityRank(task.Priority))
+	case "updated_at":
+		cursor.Value = task.UpdatedAt.Format(time.RFC3339Nano)
+	default:
+		cursor.Value = task.CreatedAt.Format(time.RFC3339Nano)
+	}
+	return EncodeCursor(cursor)
+}
+
+func EncodeCursor(cursor TaskCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeCursor(value string) (*TaskCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	var cursor TaskCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
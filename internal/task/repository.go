@@ -3,195 +3,472 @@ package task
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
-	"strings"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"sync/atomic"
 	"time"
 
 	domain "github.com/felipeversiane/task-api/internal"
+	"github.com/felipeversiane/task-api/internal/cache"
+	"github.com/felipeversiane/task-api/internal/log"
 	"github.com/felipeversiane/task-api/internal/rest"
+	"github.com/felipeversiane/task-api/internal/stream"
+	"github.com/felipeversiane/task-api/internal/tracing"
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/singleflight"
 )
 
+const (
+	defaultCacheTTL    = 24 * time.Hour
+	defaultNegativeTTL = 30 * time.Second
+	ttlJitterRatio     = 0.1
+	missingMarker      = "-"
+)
+
+type CacheStats struct {
+	Hits         atomic.Int64
+	Misses       atomic.Int64
+	NegativeHits atomic.Int64
+	Coalesced    atomic.Int64
+}
+
+type CacheStatsSnapshot struct {
+	Hits         int64 `json:"hits"`
+	Misses       int64 `json:"misses"`
+	NegativeHits int64 `json:"negative_hits"`
+	Coalesced    int64 `json:"coalesced"`
+}
+
+func (s *CacheStats) Snapshot() CacheStatsSnapshot {
+	return CacheStatsSnapshot{
+		Hits:         s.Hits.Load(),
+		Misses:       s.Misses.Load(),
+		NegativeHits: s.NegativeHits.Load(),
+		Coalesced:    s.Coalesced.Load(),
+	}
+}
+
 type TaskRepository struct {
-	Database *pgxpool.Pool
-	Cache    *redis.Client
+	Store       TaskStore
+	Cache       cache.Cache
+	TTL         time.Duration
+	NegativeTTL time.Duration
+	Stats       *CacheStats
+	Events      EventPublisher
+	group       *singleflight.Group
 }
 
-func NewTaskRepository(database *pgxpool.Pool, cache *redis.Client) TaskRepository {
+func NewTaskRepository(store TaskStore, cache cache.Cache) TaskRepository {
 	return TaskRepository{
-		Database: database,
-		Cache:    cache,
+		Store:       store,
+		Cache:       cache,
+		TTL:         defaultCacheTTL,
+		NegativeTTL: defaultNegativeTTL,
+		Stats:       &CacheStats{},
+		group:       &singleflight.Group{},
 	}
 }
 
+type lookupResult struct {
+	task *TaskResponse
+	err  *rest.RestError
+}
+
 func (r *TaskRepository) Insert(ctx context.Context, task domain.Task) (*TaskResponse, *rest.RestError) {
-	nameKey := fmt.Sprintf("task:name:%s", task.Name)
+	ctx, span := tracing.Tracer().Start(ctx, "TaskRepository.Insert")
+	defer span.End()
 
-	if v, err := r.Cache.Get(ctx, nameKey).Result(); err == nil && v != "" {
-		return nil, rest.NewBadRequestError(fmt.Sprintf("task with name %s already exists", task.Name))
+	if r.nameTaken(ctx, task.Name, uuid.Nil) {
+		return nil, rest.NewConflictError(fmt.Sprintf("task with name %s already exists", task.Name))
 	}
 
-	query := `INSERT INTO tasks (id, name, description, situation, created_at, updated_at)
-	          VALUES ($1, $2, $3, $4, $5, $6)
-	          RETURNING id, name, description, situation, created_at, updated_at`
+	created, err := r.Store.Insert(ctx, task)
+	if err != nil {
+		return nil, err
+	}
 
-	var taskResponse TaskResponse
-	err := r.Database.QueryRow(ctx, query,
-		task.ID, task.Name, task.Description, task.Situation, task.CreatedAt, task.UpdatedAt).
-		Scan(&taskResponse.ID, &taskResponse.Name, &taskResponse.Description,
-			&taskResponse.Situation, &taskResponse.CreatedAt, &taskResponse.UpdatedAt)
+	r.cacheTask(ctx, created)
+	r.publish(ctx, stream.EventCreated, nil, created)
+	return created, nil
+}
 
-	if err != nil {
-		if strings.Contains(err.Error(), "unique constraint") {
-			return nil, rest.NewBadRequestError(fmt.Sprintf("task with name %s already exists", task.Name))
-		}
-		return nil, rest.NewInternalServerError(fmt.Sprintf("%s", err))
+func (r *TaskRepository) Update(ctx context.Context, id uuid.UUID, current *TaskResponse, task domain.Task) (*TaskResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskRepository.Update")
+	defer span.End()
+
+	return r.Patch(ctx, id, current, task, updatableFields)
+}
+
+func (r *TaskRepository) Patch(ctx context.Context, id uuid.UUID, current *TaskResponse, task domain.Task, fields []string) (*TaskResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskRepository.Patch")
+	defer span.End()
+
+	if slices.Contains(fields, "name") && r.nameTaken(ctx, task.Name, id) {
+		return nil, rest.NewConflictError(fmt.Sprintf("task with name %s already exists", task.Name))
 	}
 
-	taskJSON, err := json.Marshal(taskResponse)
+	updated, err := r.Store.Patch(ctx, id, current, task, fields)
 	if err != nil {
-		return nil, rest.NewInternalServerError(fmt.Sprintf("%s", err))
+		return nil, err
+	}
+
+	if current.Name != updated.Name {
+		r.evict(ctx, nameKey(current.Name))
 	}
-	_, err = r.Cache.Set(ctx, taskResponse.ID.String(), taskJSON, 24*time.Hour).Result()
+	r.cacheTask(ctx, updated)
+	r.publish(ctx, stream.EventUpdated, current, updated)
+	return updated, nil
+}
+
+func (r *TaskRepository) Transition(ctx context.Context, current *TaskResponse, transition domain.Transition) (*TaskResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskRepository.Transition")
+	defer span.End()
+
+	updated, err := r.Store.Transition(ctx, current, transition)
 	if err != nil {
-		slog.Error(fmt.Sprintf("Failed to cache task: %v", err))
+		return nil, err
 	}
 
-	_, err = r.Cache.Set(ctx, nameKey, taskResponse.ID.String(), 24*time.Hour).Result()
+	r.cacheTask(ctx, updated)
+	r.publish(ctx, stream.EventUpdated, current, updated)
+	return updated, nil
+}
+
+func (r *TaskRepository) GetTransitions(ctx context.Context, id uuid.UUID) ([]TransitionResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskRepository.GetTransitions")
+	defer span.End()
+
+	return r.Store.GetTransitions(ctx, id)
+}
+
+func (r *TaskRepository) Delete(ctx context.Context, id uuid.UUID, version int64) *rest.RestError {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskRepository.Delete")
+	defer span.End()
+
+	task, err := r.GetByID(ctx, id)
 	if err != nil {
-		slog.Error(fmt.Sprintf("Failed to cache task name: %v", err))
+		return err
+	}
+
+	if err := r.Store.Delete(ctx, id, version); err != nil {
+		return err
 	}
 
-	return &taskResponse, nil
+	r.evict(ctx, nameKey(task.Name))
+	r.cacheMissing(ctx, id)
+	r.publish(ctx, stream.EventDeleted, nil, task)
+	return nil
 }
 
-func (r *TaskRepository) Update(ctx context.Context, id uuid.UUID, task domain.Task) (*TaskResponse, *rest.RestError) {
-	nameKey := fmt.Sprintf("task:name:%s", task.Name)
+func (r *TaskRepository) GetByID(ctx context.Context, id uuid.UUID) (*TaskResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskRepository.GetByID")
+	defer span.End()
 
-	existingID, err := r.Cache.Get(ctx, nameKey).Result()
-	if err == nil && existingID != "" && existingID != id.String() {
-		return nil, rest.NewBadRequestError(fmt.Sprintf("task with name %s already exists", task.Name))
+	if task, missing, ok := r.cachedTask(ctx, id); ok {
+		if missing {
+			r.Stats.NegativeHits.Add(1)
+			span.SetAttributes(attribute.String("cache.result", "negative_hit"))
+			return nil, rest.NewNotFoundError(fmt.Sprintf("task with ID %s not found", id))
+		}
+		r.Stats.Hits.Add(1)
+		span.SetAttributes(attribute.String("cache.result", "hit"))
+		return task, nil
 	}
+	r.Stats.Misses.Add(1)
+	span.SetAttributes(attribute.String("cache.result", "miss"))
+
+	executed := false
+	value, _, _ := r.group.Do(id.String(), func() (interface{}, error) {
+		executed = true
+		fetchCtx := context.WithoutCancel(ctx)
+
+		task, err := r.Store.GetByID(fetchCtx, id)
+		if err != nil {
+			if err.Code == http.StatusNotFound {
+				r.cacheMissing(fetchCtx, id)
+			}
+			return lookupResult{err: err}, nil
+		}
 
-	query := `UPDATE tasks SET name = $1, description = $2, situation = $3, updated_at = $4
-	          WHERE id = $5
-	          RETURNING id, name, description, situation, created_at, updated_at`
+		r.cacheTask(fetchCtx, task)
+		return lookupResult{task: task}, nil
+	})
+	if !executed {
+		r.Stats.Coalesced.Add(1)
+		span.SetAttributes(attribute.Bool("cache.coalesced", true))
+	}
+
+	result := value.(lookupResult)
+	if result.err != nil {
+		return nil, result.err
+	}
+	task := *result.task
+	return &task, nil
+}
+
+func (r *TaskRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]TaskResponse, *rest.RestError) {
+	return r.Store.GetByIDs(ctx, ids)
+}
 
-	var taskResponse TaskResponse
-	err = r.Database.QueryRow(ctx, query, task.Name, task.Description, task.Situation, task.UpdatedAt, id).
-		Scan(&taskResponse.ID, &taskResponse.Name, &taskResponse.Description, &taskResponse.Situation,
-			&taskResponse.CreatedAt, &taskResponse.UpdatedAt)
+func (r *TaskRepository) Batch(ctx context.Context, ops []BatchOperation, atomic bool) ([]BatchResult, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskRepository.Batch")
+	defer span.End()
 
+	results, err := r.Store.Batch(ctx, ops, atomic)
 	if err != nil {
-		if strings.Contains(err.Error(), "unique constraint") {
-			return nil, rest.NewBadRequestError(fmt.Sprintf("task with name %s already exists", task.Name))
-		}
-		return nil, rest.NewInternalServerError(fmt.Sprintf("%s", err))
+		return nil, err
 	}
 
-	taskJSON, err := json.Marshal(taskResponse)
+	r.cacheBatch(ctx, ops, results)
+	r.publishBatch(ctx, ops, results)
+	return results, nil
+}
+
+func (r *TaskRepository) GetAll(ctx context.Context, query TaskListQuery) ([]TaskResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskRepository.GetAll")
+	defer span.End()
+
+	return r.Store.GetAll(ctx, query)
+}
+
+func (r *TaskRepository) Search(ctx context.Context, query SearchQuery) ([]TaskSearchResult, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskRepository.Search")
+	defer span.End()
+
+	return r.Store.Search(ctx, query)
+}
+
+func (r *TaskRepository) AttachTags(ctx context.Context, current *TaskResponse, names []string) (*TaskResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskRepository.AttachTags")
+	defer span.End()
+
+	updated, err := r.Store.AttachTags(ctx, current, names)
 	if err != nil {
-		return nil, rest.NewInternalServerError(fmt.Sprintf("%s", err))
+		return nil, err
 	}
-	_, err = r.Cache.Set(ctx, id.String(), taskJSON, 24*time.Hour).Result()
+
+	r.cacheTask(ctx, updated)
+	r.publish(ctx, stream.EventUpdated, current, updated)
+	return updated, nil
+}
+
+func (r *TaskRepository) DetachTag(ctx context.Context, current *TaskResponse, name string) (*TaskResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskRepository.DetachTag")
+	defer span.End()
+
+	updated, err := r.Store.DetachTag(ctx, current, name)
 	if err != nil {
-		slog.Error(fmt.Sprintf("Failed to cache task: %v", err))
+		return nil, err
 	}
 
-	_, err = r.Cache.Set(ctx, nameKey, id.String(), 24*time.Hour).Result()
+	r.cacheTask(ctx, updated)
+	r.publish(ctx, stream.EventUpdated, current, updated)
+	return updated, nil
+}
+
+func (r *TaskRepository) CreateTag(ctx context.Context, tag domain.Tag) (*TagResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskRepository.CreateTag")
+	defer span.End()
+
+	return r.Store.CreateTag(ctx, tag)
+}
+
+func (r *TaskRepository) GetTag(ctx context.Context, id uuid.UUID) (*TagResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskRepository.GetTag")
+	defer span.End()
+
+	return r.Store.GetTag(ctx, id)
+}
+
+func (r *TaskRepository) GetTags(ctx context.Context) ([]TagResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskRepository.GetTags")
+	defer span.End()
+
+	return r.Store.GetTags(ctx)
+}
+
+func (r *TaskRepository) RenameTag(ctx context.Context, id uuid.UUID, name string) (*TagResponse, []TaskChange, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskRepository.RenameTag")
+	defer span.End()
+
+	tag, changes, err := r.Store.RenameTag(ctx, id, name)
 	if err != nil {
-		slog.Error(fmt.Sprintf("Failed to cache task name: %v", err))
+		return nil, nil, err
 	}
 
-	return &taskResponse, nil
+	r.cacheRetagged(ctx, changes)
+	return tag, changes, nil
 }
 
-func (r *TaskRepository) Delete(ctx context.Context, id uuid.UUID) *rest.RestError {
-	taskJSON, err := r.Cache.Get(ctx, id.String()).Result()
+func (r *TaskRepository) DeleteTag(ctx context.Context, id uuid.UUID) ([]TaskChange, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskRepository.DeleteTag")
+	defer span.End()
+
+	changes, err := r.Store.DeleteTag(ctx, id)
 	if err != nil {
-		return rest.NewNotFoundError(fmt.Sprintf("task with ID %s not found", id))
+		return nil, err
 	}
 
-	var task TaskResponse
-	if err := json.Unmarshal([]byte(taskJSON), &task); err != nil {
-		return rest.NewInternalServerError(fmt.Sprintf("Failed to unmarshal task: %s", err))
+	r.cacheRetagged(ctx, changes)
+	return changes, nil
+}
+
+func (r *TaskRepository) cacheRetagged(ctx context.Context, changes []TaskChange) {
+	for _, change := range changes {
+		r.cacheTask(ctx, change.After)
+		r.publish(ctx, stream.EventUpdated, change.Before, change.After)
 	}
+}
 
-	if _, err := r.Cache.Del(ctx, id.String()).Result(); err != nil {
-		slog.Error(fmt.Sprintf("Failed to delete task from cache: %v", err))
+func (r *TaskRepository) cachedTask(ctx context.Context, id uuid.UUID) (*TaskResponse, bool, bool) {
+	value, err := r.Cache.Get(ctx, taskKey(id))
+	if err != nil {
+		if !errors.Is(err, cache.ErrMiss) {
+			log.FromContext(ctx).Error("Failed to read task from cache", slog.Any("error", err))
+		}
+		return nil, false, false
 	}
 
-	nameKey := fmt.Sprintf("task:name:%s", task.Name)
-	if _, err := r.Cache.Del(ctx, nameKey).Result(); err != nil {
-		slog.Error(fmt.Sprintf("Failed to delete task name from cache: %v", err))
+	if string(value) == missingMarker {
+		return nil, true, true
 	}
 
-	query := `DELETE FROM tasks WHERE id = $1 RETURNING id`
-	var deletedID uuid.UUID
-	if err := r.Database.QueryRow(ctx, query, id).Scan(&deletedID); err != nil {
-		if err == pgx.ErrNoRows {
-			return rest.NewNotFoundError(fmt.Sprintf("task with ID %s not found", id))
-		}
-		return rest.NewInternalServerError(fmt.Sprintf("%s", err))
+	var task TaskResponse
+	if err := json.Unmarshal(value, &task); err != nil || task.Version == 0 {
+		return nil, false, false
 	}
+	return &task, false, true
+}
 
-	return nil
+func (r *TaskRepository) cacheMissing(ctx context.Context, id uuid.UUID) {
+	if err := r.Cache.Set(ctx, taskKey(id), []byte(missingMarker), r.NegativeTTL); err != nil {
+		log.FromContext(ctx).Error("Failed to cache missing task", slog.Any("error", err))
+	}
 }
 
-func (r *TaskRepository) GetByID(ctx context.Context, id uuid.UUID) (*TaskResponse, *rest.RestError) {
-	taskJSON, err := r.Cache.Get(ctx, id.String()).Result()
-	if err == nil {
-		var task TaskResponse
-		if err := json.Unmarshal([]byte(taskJSON), &task); err == nil {
-			return &task, nil
-		}
+func (r *TaskRepository) cacheTask(ctx context.Context, task *TaskResponse) {
+	entries, err := r.taskEntries(task)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to marshal task", slog.Any("error", err))
+		return
 	}
 
-	query := `SELECT id, name, description, situation, created_at, updated_at FROM tasks WHERE id = $1`
-	row := r.Database.QueryRow(ctx, query, id)
-	var task TaskResponse
-	if err := row.Scan(&task.ID, &task.Name, &task.Description, &task.Situation, &task.CreatedAt, &task.UpdatedAt); err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, rest.NewNotFoundError(fmt.Sprintf("task with ID %s not found", id))
+	if err := r.Cache.SetMany(ctx, entries); err != nil {
+		log.FromContext(ctx).Error("Failed to cache task", slog.Any("error", err))
+	}
+}
+
+func (r *TaskRepository) cacheBatch(ctx context.Context, ops []BatchOperation, results []BatchResult) {
+	byIndex := make(map[int]BatchOperation, len(ops))
+	for _, op := range ops {
+		byIndex[op.Index] = op
+	}
+
+	var entries []cache.Entry
+	var evicted []string
+	for _, result := range results {
+		if result.Error != nil {
+			continue
+		}
+
+		op := byIndex[result.Index]
+		if op.Op == BatchDelete {
+			evicted = append(evicted, nameKey(op.Current.Name))
+			entries = append(entries, cache.Entry{Key: taskKey(op.Current.ID), Value: []byte(missingMarker), TTL: r.NegativeTTL})
+			continue
+		}
+
+		if op.Current != nil && op.Current.Name != result.Task.Name {
+			evicted = append(evicted, nameKey(op.Current.Name))
+		}
+		taskEntries, err := r.taskEntries(result.Task)
+		if err != nil {
+			log.FromContext(ctx).Error("Failed to marshal task", slog.Any("error", err))
+			continue
 		}
-		return nil, rest.NewInternalServerError(fmt.Sprintf("%s", err))
+		entries = append(entries, taskEntries...)
 	}
 
-	taskBytes, err := json.Marshal(task)
-	if err != nil {
-		return nil, rest.NewInternalServerError(fmt.Sprintf("%s", err))
+	if len(evicted) > 0 {
+		r.evict(ctx, evicted...)
+	}
+	if err := r.Cache.SetMany(ctx, entries); err != nil {
+		log.FromContext(ctx).Error("Failed to cache batch", slog.Any("error", err))
 	}
-	_, err = r.Cache.Set(ctx, id.String(), string(taskBytes), 24*time.Hour).Result()
+}
+
+func (r *TaskRepository) taskEntries(task *TaskResponse) ([]cache.Entry, error) {
+	taskJSON, err := json.Marshal((*storedTask)(task))
 	if err != nil {
-		slog.Error(fmt.Sprintf("Failed to cache task: %v", err))
+		return nil, err
 	}
 
-	return &task, nil
+	ttl := jitter(r.TTL)
+	return []cache.Entry{
+		{Key: taskKey(task.ID), Value: taskJSON, TTL: ttl},
+		{Key: nameKey(task.Name), Value: []byte(task.ID.String()), TTL: ttl},
+	}, nil
 }
 
-func (r *TaskRepository) GetAll(ctx context.Context) ([]TaskResponse, *rest.RestError) {
-	rows, err := r.Database.Query(ctx, `SELECT id, name, description, situation, created_at, updated_at FROM tasks`)
-	if err != nil {
-		return nil, rest.NewInternalServerError(fmt.Sprintf("%s", err))
+func (r *TaskRepository) publishBatch(ctx context.Context, ops []BatchOperation, results []BatchResult) {
+	byIndex := make(map[int]BatchOperation, len(ops))
+	for _, op := range ops {
+		byIndex[op.Index] = op
 	}
-	defer rows.Close()
 
-	var tasks []TaskResponse
-	for rows.Next() {
-		var task TaskResponse
-		if err := rows.Scan(&task.ID, &task.Name, &task.Description, &task.Situation, &task.CreatedAt, &task.UpdatedAt); err != nil {
-			return nil, rest.NewInternalServerError(fmt.Sprintf("%s", err))
+	for _, result := range results {
+		if result.Error != nil {
+			continue
+		}
+		switch op := byIndex[result.Index]; op.Op {
+		case BatchCreate:
+			r.publish(ctx, stream.EventCreated, nil, result.Task)
+		case BatchUpdate:
+			r.publish(ctx, stream.EventUpdated, op.Current, result.Task)
+		case BatchDelete:
+			r.publish(ctx, stream.EventDeleted, nil, op.Current)
 		}
-		tasks = append(tasks, task)
 	}
+}
+
+func (r *TaskRepository) publish(ctx context.Context, eventType string, before *TaskResponse, task *TaskResponse) {
+	if r.Events == nil {
+		return
+	}
+	r.Events.Publish(context.WithoutCancel(ctx), eventType, task.ID, StreamTask{TaskResponse: task, Before: before})
+}
+
+func (r *TaskRepository) nameTaken(ctx context.Context, name string, id uuid.UUID) bool {
+	value, err := r.Cache.Get(ctx, nameKey(name))
+	if err != nil {
+		return false
+	}
+	return len(value) > 0 && string(value) != id.String()
+}
+
+func (r *TaskRepository) evict(ctx context.Context, keys ...string) {
+	if err := r.Cache.Del(ctx, keys...); err != nil {
+		log.FromContext(ctx).Error("Failed to delete task from cache", slog.Any("error", err))
+	}
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, rest.NewInternalServerError(fmt.Sprintf("%s", err))
+func jitter(ttl time.Duration) time.Duration {
+	spread := int64(float64(ttl) * ttlJitterRatio)
+	if spread <= 0 {
+		return ttl
 	}
+	return ttl - time.Duration(spread) + time.Duration(rand.Int64N(2*spread+1))
+}
+
+func taskKey(id uuid.UUID) string {
+	return fmt.Sprintf("task:id:%s", id)
+}
 
-	return tasks, nil
+func nameKey(name string) string {
+	return fmt.Sprintf("task:name:%s", name)
 }
@@ -0,0 +1,112 @@
+package task
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/felipeversiane/task-api/internal/cache"
+	"github.com/felipeversiane/task-api/internal/rest"
+	"github.com/google/uuid"
+)
+
+type slowStore struct {
+	*MemoryStore
+	delay time.Duration
+	calls atomic.Int64
+}
+
+func (s *slowStore) GetByID(ctx context.Context, id uuid.UUID) (*TaskResponse, *rest.RestError) {
+	s.calls.Add(1)
+	time.Sleep(s.delay)
+	return s.MemoryStore.GetByID(ctx, id)
+}
+
+func TestTaskRepository_ShouldCoalesceConcurrentMisses(t *testing.T) {
+	ctx := context.Background()
+	store := &slowStore{MemoryStore: NewMemoryStore(), delay: 50 * time.Millisecond}
+	created, err := store.Insert(ctx, RequestToDomainTask(TaskRequest{Name: "Hot task", Situation: "not started"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repository := NewTaskRepository(store, cache.NewLRUCache(100))
+
+	const callers = 20
+	var start, done sync.WaitGroup
+	start.Add(1)
+	for i := 0; i < callers; i++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			start.Wait()
+			if _, err := repository.GetByID(ctx, created.ID); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	start.Done()
+	done.Wait()
+
+	if calls := store.calls.Load(); calls != 1 {
+		t.Fatalf("Expected 1 store call and received %d", calls)
+	}
+	stats := repository.Stats.Snapshot()
+	if stats.Misses+stats.Hits != callers || stats.Coalesced != stats.Misses-1 {
+		t.Fatalf("Unexpected cache stats %+v", stats)
+	}
+}
+
+func TestTaskRepository_ShouldCacheNotFound(t *testing.T) {
+	ctx := context.Background()
+	store := &slowStore{MemoryStore: NewMemoryStore()}
+	repository := NewTaskRepository(store, cache.NewLRUCache(100))
+
+	id := uuid.New()
+	for i := 0; i < 3; i++ {
+		if _, err := repository.GetByID(ctx, id); err == nil || err.Code != http.StatusNotFound {
+			t.Fatalf("Expected not found and received %v", err)
+		}
+	}
+
+	if calls := store.calls.Load(); calls != 1 {
+		t.Fatalf("Expected 1 store call and received %d", calls)
+	}
+	if stats := repository.Stats.Snapshot(); stats.NegativeHits != 2 {
+		t.Fatalf("Expected 2 negative hits and received %+v", stats)
+	}
+}
+
+func TestJitter_ShouldStayWithinBounds(t *testing.T) {
+	ttl := time.Hour
+	for i := 0; i < 100; i++ {
+		got := jitter(ttl)
+		if got < ttl-ttl/10 || got > ttl+ttl/10 {
+			t.Fatalf("Jittered TTL %s out of bounds", got)
+		}
+	}
+}
+
+func TestTaskRepository_ShouldNotCacheOverdueFlag(t *testing.T) {
+	ctx := context.Background()
+	lru := cache.NewLRUCache(100)
+	repository := NewTaskRepository(NewMemoryStore(), lru)
+
+	dueAt := time.Now().Add(-time.Hour)
+	created, err := repository.Insert(ctx, RequestToDomainTask(TaskRequest{Name: "Late task", Situation: "not started", DueAt: &dueAt}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, cacheErr := lru.Get(ctx, taskKey(created.ID))
+	if cacheErr != nil {
+		t.Fatal(cacheErr)
+	}
+	if strings.Contains(string(value), "overdue") {
+		t.Fatalf("Expected the cached task to omit the overdue flag and received %s", value)
+	}
+}
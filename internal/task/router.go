@@ -1,20 +1,82 @@
 package task
 
 import (
+	"expvar"
 	"net/http"
+	"sync"
+	"sync/atomic"
 
 	"github.com/felipeversiane/task-api/internal/cache"
 	"github.com/felipeversiane/task-api/internal/database"
+	"github.com/felipeversiane/task-api/internal/idempotency"
+	"github.com/felipeversiane/task-api/internal/metrics"
+	"github.com/felipeversiane/task-api/internal/stream"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var Handler TaskHandler
 
-func TasksRouter(mux *http.ServeMux) {
-	Handler = NewTaskHandler(NewTaskService(NewTaskRepository(database.Connection, cache.Client)))
+var (
+	publishCacheStats sync.Once
+	cacheStats        atomic.Pointer[CacheStats]
+)
+
+func TasksRouter(mux *http.ServeMux, broker *stream.Broker) {
+	repository := NewTaskRepository(NewPostgresStore(database.Connection), cache.Instance)
+	repository.Events = broker
+	cacheStats.Store(repository.Stats)
+	publishCacheStats.Do(func() {
+		expvar.Publish("task_cache", expvar.Func(func() any {
+			return cacheStats.Load().Snapshot()
+		}))
+		registerCacheMetrics(metrics.Default, cacheStats.Load)
+	})
+	service := NewTaskService(&repository)
+	Handler = NewTaskHandler(service)
+	Handler.Idempotency = idempotencyStore()
+	Handler.Stream = broker
+	RegisterRoutes(mux, &Handler)
+}
+
+func RegisterRoutes(mux *http.ServeMux, handler *TaskHandler) {
+	mux.HandleFunc("POST /api/v1/tasks", handler.idempotent(handler.PostTask))
+	mux.HandleFunc("POST /api/v1/tasks:batch", handler.BatchTasks)
+	mux.HandleFunc("PUT /api/v1/tasks/{id}", handler.UpdateTask)
+	mux.HandleFunc("PATCH /api/v1/tasks/{id}", handler.PatchTask)
+	mux.HandleFunc("DELETE /api/v1/tasks/{id}", handler.DeleteTask)
+	mux.HandleFunc("GET /api/v1/tasks/{id}", handler.GetTaskByID)
+	mux.HandleFunc("GET /api/v1/tasks", handler.GetAllTasks)
+	mux.HandleFunc("GET /api/v1/tasks/search", handler.SearchTasks)
+	mux.HandleFunc("GET /api/v1/tasks/events", handler.StreamEvents)
+	mux.HandleFunc("GET /api/v1/tasks/ws", handler.ServeSocket)
+	mux.HandleFunc("POST /api/v1/tasks/{id}/transitions", handler.TransitionTask)
+	mux.HandleFunc("GET /api/v1/tasks/{id}/transitions", handler.GetTaskTransitions)
+	mux.HandleFunc("POST /api/v1/tasks/{id}/tags", handler.AttachTags)
+	mux.HandleFunc("DELETE /api/v1/tasks/{id}/tags/{tag}", handler.DetachTag)
+	mux.HandleFunc("POST /api/v1/tags", handler.PostTag)
+	mux.HandleFunc("GET /api/v1/tags", handler.GetAllTags)
+	mux.HandleFunc("GET /api/v1/tags/{id}", handler.GetTagByID)
+	mux.HandleFunc("PUT /api/v1/tags/{id}", handler.UpdateTag)
+	mux.HandleFunc("DELETE /api/v1/tags/{id}", handler.DeleteTag)
+}
+
+func idempotencyStore() idempotency.Store {
+	store := idempotency.NewPostgresStore(database.Connection)
+	if cache.Client == nil {
+		return store
+	}
+	return idempotency.NewFallbackStore(idempotency.NewRedisStore(cache.Client), store)
+}
 
-	mux.HandleFunc("POST /api/v1/tasks", Handler.PostTask)
-	mux.HandleFunc("PUT /api/v1/tasks/{id}", Handler.UpdateTask)
-	mux.HandleFunc("DELETE /api/v1/tasks/{id}", Handler.DeleteTask)
-	mux.HandleFunc("GET /api/v1/tasks/{id}", Handler.GetTaskByID)
-	mux.HandleFunc("GET /api/v1/tasks", Handler.GetAllTasks)
+func registerCacheMetrics(registry prometheus.Registerer, stats func() *CacheStats) {
+	registry.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{Name: "task_cache_hits_total", Help: "Task lookups served from the cache."},
+			func() float64 { return float64(stats().Snapshot().Hits) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{Name: "task_cache_misses_total", Help: "Task lookups that missed the cache."},
+			func() float64 { return float64(stats().Snapshot().Misses) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{Name: "task_cache_negative_hits_total", Help: "Task lookups answered by a cached not-found entry."},
+			func() float64 { return float64(stats().Snapshot().NegativeHits) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{Name: "task_cache_coalesced_total", Help: "Task cache misses that waited on an in-flight lookup."},
+			func() float64 { return float64(stats().Snapshot().Coalesced) }),
+	)
 }
@@ -0,0 +1,165 @@
+package task
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+
+	domain "github.com/felipeversiane/task-api/internal"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+type SearchQuery struct {
+	Terms              []string
+	Phrases            []string
+	ExcludedTerms      []string
+	ExcludedPhrases    []string
+	Situations         []domain.Situation
+	ExcludedSituations []domain.Situation
+	Limit              int
+}
+
+// TaskHighlights holds HTML-escaped text with matches wrapped in <mark>.
+type TaskHighlights struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type TaskSearchResult struct {
+	Task       TaskResponse   `json:"task"`
+	Rank       float32        `json:"rank"`
+	Highlights TaskHighlights `json:"highlights"`
+}
+
+type TaskSearchPage struct {
+	Data []TaskSearchResult `json:"data"`
+}
+
+func ParseTaskSearchQuery(values url.Values) (SearchQuery, error) {
+	query, err := ParseSearchQuery(values.Get("q"))
+	if err != nil {
+		return query, err
+	}
+
+	query.Limit = defaultSearchLimit
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxSearchLimit {
+			return query, fmt.Errorf("limit must be an integer between 1 and %d", maxSearchLimit)
+		}
+		query.Limit = n
+	}
+	return query, nil
+}
+
+func ParseSearchQuery(q string) (SearchQuery, error) {
+	var query SearchQuery
+	runes := []rune(q)
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		negated := false
+		if runes[i] == '-' {
+			negated = true
+			i++
+		}
+
+		if i < len(runes) && runes[i] == '"' {
+			phrase, next, err := readQuoted(runes, i)
+			if err != nil {
+				return query, err
+			}
+			i = next
+			if phrase == "" {
+				continue
+			}
+			if negated {
+				query.ExcludedPhrases = append(query.ExcludedPhrases, phrase)
+			} else {
+				query.Phrases = append(query.Phrases, phrase)
+			}
+			continue
+		}
+
+		start := i
+		for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != ':' {
+			i++
+		}
+		word := string(runes[start:i])
+
+		if i < len(runes) && runes[i] == ':' {
+			i++
+			var value string
+			if i < len(runes) && runes[i] == '"' {
+				var err error
+				value, i, err = readQuoted(runes, i)
+				if err != nil {
+					return query, err
+				}
+			} else {
+				start := i
+				for i < len(runes) && !unicode.IsSpace(runes[i]) {
+					i++
+				}
+				value = string(runes[start:i])
+			}
+			if err := query.addQualifier(word, value, negated); err != nil {
+				return query, err
+			}
+			continue
+		}
+
+		if word == "" {
+			continue
+		}
+		if negated {
+			query.ExcludedTerms = append(query.ExcludedTerms, word)
+		} else {
+			query.Terms = append(query.Terms, word)
+		}
+	}
+
+	if len(query.Terms) == 0 && len(query.Phrases) == 0 {
+		return query, fmt.Errorf("search query must contain at least one term or phrase")
+	}
+	return query, nil
+}
+
+func (q *SearchQuery) addQualifier(key string, value string, negated bool) error {
+	switch strings.ToLower(key) {
+	case "situation":
+		situation := domain.Situation(strings.ToLower(value))
+		if !domain.IsValidSituation(situation) {
+			return fmt.Errorf("invalid situation value %q", value)
+		}
+		if negated {
+			q.ExcludedSituations = append(q.ExcludedSituations, situation)
+		} else {
+			q.Situations = append(q.Situations, situation)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown search qualifier %q", key)
+	}
+}
+
+func readQuoted(runes []rune, i int) (string, int, error) {
+	end := i + 1
+	for end < len(runes) && runes[end] != '"' {
+		end++
+	}
+	if end >= len(runes) {
+		return "", end, fmt.Errorf("unterminated quoted phrase")
+	}
+	return strings.TrimSpace(string(runes[i+1 : end])), end + 1, nil
+}
@@ -0,0 +1,83 @@
+package task
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	domain "github.com/felipeversiane/task-api/internal"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	cases := map[string]SearchQuery{
+		"invoice":                   {Terms: []string{"invoice"}},
+		"  invoice   report ":       {Terms: []string{"invoice", "report"}},
+		`"quarterly report" -draft`: {Phrases: []string{"quarterly report"}, ExcludedTerms: []string{"draft"}},
+		`invoice -"old draft"`:      {Terms: []string{"invoice"}, ExcludedPhrases: []string{"old draft"}},
+		`invoice ""`:                {Terms: []string{"invoice"}},
+		"invoice -":                 {Terms: []string{"invoice"}},
+		`invoice situation:"In Progress" -situation:completed`: {
+			Terms:              []string{"invoice"},
+			Situations:         []domain.Situation{domain.SituationInProgress},
+			ExcludedSituations: []domain.Situation{domain.SituationCompleted},
+		},
+	}
+	for q, expected := range cases {
+		query, err := ParseSearchQuery(q)
+		if err != nil {
+			t.Fatalf("ParseSearchQuery(%q) returned %v", q, err)
+		}
+		if !reflect.DeepEqual(query, expected) {
+			t.Fatalf("ParseSearchQuery(%q) = %+v, expected %+v", q, query, expected)
+		}
+	}
+}
+
+func TestParseSearchQuery_ShouldRejectInvalidQueries(t *testing.T) {
+	cases := map[string]string{
+		`"unclosed phrase`:               "unterminated quoted phrase",
+		`invoice situation:"in progress`: "unterminated quoted phrase",
+		"-":                              "search query must contain at least one term or phrase",
+		"invoice owner:me":               `unknown search qualifier "owner"`,
+		"invoice situation:done":         `invalid situation value "done"`,
+		"invoice situation:":             `invalid situation value ""`,
+		"-draft -\"old report\"":         "search query must contain at least one term or phrase",
+		"situation:completed":            "search query must contain at least one term or phrase",
+		`""`:                             "search query must contain at least one term or phrase",
+		"":                               "search query must contain at least one term or phrase",
+	}
+	for q, expected := range cases {
+		_, err := ParseSearchQuery(q)
+		if err == nil || err.Error() != expected {
+			t.Fatalf("ParseSearchQuery(%q) returned %v, expected %q", q, err, expected)
+		}
+	}
+}
+
+func TestSearch_ShouldEscapeHighlightedText(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	if _, err := store.Insert(ctx, RequestToDomainTask(TaskRequest{
+		Name:        `<img src=x onerror=alert(1)> invoice`,
+		Description: `Pay the "invoice" & file it`,
+		Situation:   "not started",
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := store.Search(ctx, SearchQuery{Terms: []string{"invoice"}, Limit: defaultSearchLimit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result and received %d", len(results))
+	}
+
+	highlights := results[0].Highlights
+	if expected := `&lt;img src=x onerror=alert(1)&gt; <mark>invoice</mark>`; highlights.Name != expected {
+		t.Fatalf("Expected name highlight %q and received %q", expected, highlights.Name)
+	}
+	if expected := `Pay the &#34;<mark>invoice</mark>&#34; &amp; file it`; highlights.Description != expected {
+		t.Fatalf("Expected description highlight %q and received %q", expected, highlights.Description)
+	}
+}
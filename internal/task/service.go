@@ -1,78 +1,484 @@
 package task
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
 
+	domain "github.com/felipeversiane/task-api/internal"
+	"github.com/felipeversiane/task-api/internal/log"
+	"github.com/felipeversiane/task-api/internal/patch"
 	"github.com/felipeversiane/task-api/internal/rest"
+	"github.com/felipeversiane/task-api/internal/tracing"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type TaskService struct {
-	Repository TaskRepository
+	Store TaskStore
 }
 
-func NewTaskService(repository TaskRepository) TaskService {
+func NewTaskService(store TaskStore) TaskService {
 	return TaskService{
-		Repository: repository,
+		Store: store,
 	}
 }
 
 func (s *TaskService) CreateTask(ctx context.Context, req TaskRequest) (*TaskResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskService.CreateTask")
+	defer span.End()
 
-	if err := req.Validate(); err != nil {
-		return nil, rest.NewBadRequestError(err.Error())
+	domain := RequestToDomainTask(req)
+	if err := rest.ValidationError(req.Validate(), domain.ValidateFields(), domain.ValidateInitialSituation()); err != nil {
+		return nil, err
 	}
 
-	domain := RequestToDomainTask(req)
-	if err := domain.ValidateFields(); err != nil {
-		return nil, rest.NewBadRequestError(err.Error())
+	task, err := s.Store.Insert(ctx, domain)
+	if err != nil {
+		return nil, logFailure(ctx, "create", err)
+	}
+	return task, nil
+}
+
+func (s *TaskService) UpdateTask(ctx context.Context, id uuid.UUID, req UpdateTaskRequest, ifMatch string) (*TaskResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskService.UpdateTask")
+	defer span.End()
+
+	updated := RequestToUpdateDomainTask(req)
+	if err := rest.ValidationError(req.Validate(), updated.ValidateFields()); err != nil {
+		return nil, err
 	}
 
-	task, err := s.Repository.Insert(ctx, domain)
+	current, err := s.Store.GetByID(ctx, id)
 	if err != nil {
+		return nil, logFailure(ctx, "get", err)
+	}
+	if err := checkIfMatch(ifMatch, current); err != nil {
 		return nil, err
 	}
+	keepOmittedFields(&updated, req, current)
+
+	if current.Situation != updated.Situation {
+		if err := domain.ValidateTransition(current.Situation, updated.Situation); err != nil {
+			return nil, rest.NewConflictError(err.Error())
+		}
+	}
+
+	task, err := s.Store.Update(ctx, id, current, updated)
+	if err != nil {
+		return nil, logFailure(ctx, "update", modifiedError(err, ifMatch))
+	}
 	return task, nil
 }
 
-func (s *TaskService) UpdateTask(ctx context.Context, id uuid.UUID, req UpdateTaskRequest) (*TaskResponse, *rest.RestError) {
+func (s *TaskService) PatchTask(ctx context.Context, id uuid.UUID, contentType string, body []byte, ifMatch string) (*TaskResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskService.PatchTask")
+	defer span.End()
+
+	current, err := s.Store.GetByID(ctx, id)
+	if err != nil {
+		return nil, logFailure(ctx, "get", err)
+	}
+	if err := checkIfMatch(ifMatch, current); err != nil {
+		return nil, err
+	}
+
+	doc, marshalErr := json.Marshal(UpdateTaskRequest{
+		Name:        current.Name,
+		Description: current.Description,
+		Situation:   current.Situation,
+		Priority:    &current.Priority,
+		DueAt:       current.DueAt,
+	})
+	if marshalErr != nil {
+		return nil, rest.NewInternalServerError(marshalErr.Error())
+	}
+
+	var patched []byte
+	var patchErr error
+	switch contentType {
+	case patch.MergePatchContentType:
+		patched, patchErr = patch.MergePatch(doc, body)
+	case patch.JSONPatchContentType:
+		patched, patchErr = patch.JSONPatch(doc, body)
+	default:
+		return nil, rest.NewUnsupportedMediaTypeError(fmt.Sprintf("unsupported patch content type %q", contentType))
+	}
+	if patchErr != nil {
+		if errors.Is(patchErr, patch.ErrTestFailed) {
+			return nil, rest.NewConflictError(patchErr.Error())
+		}
+		return nil, rest.NewBadRequestError(patchErr.Error())
+	}
 
-	if err := req.Validate(); err != nil {
-		rest.NewBadRequestError(err.Error())
+	var req UpdateTaskRequest
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return nil, rest.NewBadRequestError(fmt.Sprintf("invalid patched task: %s", err))
 	}
 
-	domain := RequestToUpdateDomainTask(req)
-	if err := domain.ValidateFields(); err != nil {
-		rest.NewBadRequestError(err.Error())
+	updated := RequestToUpdateDomainTask(req)
+	if err := rest.ValidationError(updated.ValidateFields()); err != nil {
+		return nil, err
 	}
 
-	task, err := s.Repository.Update(ctx, id, domain)
+	var fields []string
+	if updated.Name != current.Name {
+		fields = append(fields, "name")
+	}
+	if updated.Description != current.Description {
+		fields = append(fields, "description")
+	}
+	if updated.Situation != current.Situation {
+		if err := domain.ValidateTransition(current.Situation, updated.Situation); err != nil {
+			return nil, rest.NewConflictError(err.Error())
+		}
+		fields = append(fields, "situation")
+	}
+	if updated.Priority != current.Priority {
+		fields = append(fields, "priority")
+	}
+	if !sameDueAt(updated.DueAt, current.DueAt) {
+		fields = append(fields, "due_at")
+	}
+
+	if len(fields) == 0 {
+		return current, nil
+	}
+
+	task, err := s.Store.Patch(ctx, id, current, updated, fields)
 	if err != nil {
+		return nil, logFailure(ctx, "patch", modifiedError(err, ifMatch))
+	}
+	return task, nil
+}
+
+func (s *TaskService) TransitionTask(ctx context.Context, id uuid.UUID, req TransitionRequest) (*TaskResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskService.TransitionTask")
+	defer span.End()
+
+	if err := rest.ValidationError(req.Validate()); err != nil {
 		return nil, err
 	}
+
+	current, err := s.Store.GetByID(ctx, id)
+	if err != nil {
+		return nil, logFailure(ctx, "get", err)
+	}
+
+	if err := domain.ValidateTransition(current.Situation, req.Situation); err != nil {
+		return nil, rest.NewConflictError(err.Error())
+	}
+
+	transition := domain.NewTransition(id, current.Situation, req.Situation, req.Reason)
+	task, err := s.Store.Transition(ctx, current, transition)
+	if err != nil {
+		return nil, logFailure(ctx, "transition", modifiedError(err, ""))
+	}
 	return task, nil
 }
 
-func (s *TaskService) DeleteTask(ctx context.Context, id uuid.UUID) *rest.RestError {
-	_, err := s.Repository.GetByID(ctx, id)
+func (s *TaskService) GetTaskTransitions(ctx context.Context, id uuid.UUID) ([]TransitionResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskService.GetTaskTransitions")
+	defer span.End()
+
+	if _, err := s.Store.GetByID(ctx, id); err != nil {
+		return nil, logFailure(ctx, "get", err)
+	}
+
+	transitions, err := s.Store.GetTransitions(ctx, id)
 	if err != nil {
+		return nil, logFailure(ctx, "get_transitions", err)
+	}
+	return transitions, nil
+}
+
+func (s *TaskService) DeleteTask(ctx context.Context, id uuid.UUID, ifMatch string) *rest.RestError {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskService.DeleteTask")
+	defer span.End()
+
+	current, err := s.Store.GetByID(ctx, id)
+	if err != nil {
+		return logFailure(ctx, "get", err)
+	}
+	if err := checkIfMatch(ifMatch, current); err != nil {
 		return err
 	}
-	return s.Repository.Delete(ctx, id)
+	if err := s.Store.Delete(ctx, id, current.Version); err != nil {
+		return logFailure(ctx, "delete", modifiedError(err, ifMatch))
+	}
+	return nil
 }
 
 func (s *TaskService) GetTaskByID(ctx context.Context, id uuid.UUID) (*TaskResponse, *rest.RestError) {
-	task, err := s.Repository.GetByID(ctx, id)
+	ctx, span := tracing.Tracer().Start(ctx, "TaskService.GetTaskByID")
+	defer span.End()
+
+	task, err := s.Store.GetByID(ctx, id)
+	if err != nil {
+		return nil, logFailure(ctx, "get", err)
+	}
+	return task, nil
+}
+
+func (s *TaskService) GetAllTasks(ctx context.Context, query TaskListQuery) (*TaskPage, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskService.GetAllTasks")
+	defer span.End()
+
+	tasks, err := s.Store.GetAll(ctx, query)
+	if err != nil {
+		return nil, logFailure(ctx, "list", err)
+	}
+
+	page := TaskPage{Data: tasks}
+	if len(tasks) > query.Limit {
+		page.Data = tasks[:query.Limit]
+		page.NextCursor = query.NextCursor(page.Data[query.Limit-1])
+	}
+	return &page, nil
+}
+
+func (s *TaskService) SearchTasks(ctx context.Context, query SearchQuery) (*TaskSearchPage, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskService.SearchTasks")
+	defer span.End()
+
+	results, err := s.Store.Search(ctx, query)
 	if err != nil {
+		return nil, logFailure(ctx, "search", err)
+	}
+	return &TaskSearchPage{Data: results}, nil
+}
+
+func (s *TaskService) BatchTasks(ctx context.Context, req BatchRequest) (*BatchResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskService.BatchTasks")
+	defer span.End()
+
+	if len(req.Operations) == 0 {
+		return nil, rest.NewBadRequestError("operations cannot be empty")
+	}
+	if len(req.Operations) > maxBatchOperations {
+		return nil, rest.NewBadRequestError(fmt.Sprintf("a batch accepts at most %d operations", maxBatchOperations))
+	}
+
+	results := make([]BatchResult, len(req.Operations))
+	fail := func(index int, err *rest.RestError) *rest.RestError {
+		results[index] = batchFailure(index, req.Operations[index].Op, err)
+		if req.Atomic {
+			return batchError(index, err)
+		}
+		return nil
+	}
+
+	var ops []BatchOperation
+	var ids []uuid.UUID
+	names := map[string]bool{}
+	seen := map[uuid.UUID]bool{}
+	for i, opReq := range req.Operations {
+		op, err := prepareBatchOperation(i, opReq)
+		switch {
+		case err != nil:
+		case op.Op != BatchDelete && names[op.Task.Name]:
+			err = rest.NewConflictError(fmt.Sprintf("task with name %s appears more than once in the batch", op.Task.Name))
+		case op.Op != BatchCreate && seen[op.Task.ID]:
+			err = rest.NewBadRequestError(fmt.Sprintf("task with ID %s appears more than once in the batch", op.Task.ID))
+		}
+		if err != nil {
+			if atomicErr := fail(i, err); atomicErr != nil {
+				return nil, atomicErr
+			}
+			continue
+		}
+
+		if op.Op != BatchDelete {
+			names[op.Task.Name] = true
+		}
+		if op.Op != BatchCreate {
+			seen[op.Task.ID] = true
+			ids = append(ids, op.Task.ID)
+		}
+		ops = append(ops, op)
+	}
+
+	currents, err := s.Store.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, logFailure(ctx, "batch_get", err)
+	}
+
+	ready := ops[:0]
+	for _, op := range ops {
+		if op.Op != BatchCreate {
+			current, ok := currents[op.Task.ID]
+			var opErr *rest.RestError
+			switch {
+			case !ok:
+				opErr = rest.NewNotFoundError(fmt.Sprintf("task with ID %s not found", op.Task.ID))
+			case op.Version != 0 && op.Version != current.Version:
+				opErr = rest.NewPreconditionFailedError(fmt.Sprintf("task with ID %s has been modified", op.Task.ID))
+			case op.Op == BatchUpdate && current.Situation != op.Task.Situation:
+				if err := domain.ValidateTransition(current.Situation, op.Task.Situation); err != nil {
+					opErr = rest.NewConflictError(err.Error())
+				}
+			}
+			if opErr != nil {
+				if atomicErr := fail(op.Index, opErr); atomicErr != nil {
+					return nil, atomicErr
+				}
+				continue
+			}
+			op.Current = &current
+			if op.Op == BatchUpdate {
+				keepOmittedFields(&op.Task, op.Update, op.Current)
+			}
+		}
+		ready = append(ready, op)
+	}
+
+	stored, err := s.Store.Batch(ctx, ready, req.Atomic)
+	if err != nil {
+		return nil, logFailure(ctx, "batch", err)
+	}
+	for _, result := range stored {
+		results[result.Index] = result
+	}
+	return &BatchResponse{Results: results}, nil
+}
+
+func (s *TaskService) AttachTags(ctx context.Context, id uuid.UUID, req AttachTagsRequest, ifMatch string) (*TaskResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskService.AttachTags")
+	defer span.End()
+
+	if err := rest.ValidationError(req.Validate()); err != nil {
+		return nil, err
+	}
+
+	current, err := s.Store.GetByID(ctx, id)
+	if err != nil {
+		return nil, logFailure(ctx, "get", err)
+	}
+	if err := checkIfMatch(ifMatch, current); err != nil {
+		return nil, err
+	}
+
+	names := req.Names()
+	if slices.Equal(withTags(current.Tags, names...), current.Tags) {
+		return current, nil
+	}
+
+	task, err := s.Store.AttachTags(ctx, current, names)
+	if err != nil {
+		return nil, logFailure(ctx, "attach_tags", modifiedError(err, ifMatch))
+	}
+	return task, nil
+}
+
+func (s *TaskService) DetachTag(ctx context.Context, id uuid.UUID, name string, ifMatch string) (*TaskResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskService.DetachTag")
+	defer span.End()
+
+	current, err := s.Store.GetByID(ctx, id)
+	if err != nil {
+		return nil, logFailure(ctx, "get", err)
+	}
+	if err := checkIfMatch(ifMatch, current); err != nil {
 		return nil, err
 	}
+
+	name = domain.NormalizeTagName(name)
+	if !slices.Contains(current.Tags, name) {
+		return nil, rest.NewNotFoundError(fmt.Sprintf("tag %s is not attached to task with ID %s", name, id))
+	}
+
+	task, err := s.Store.DetachTag(ctx, current, name)
+	if err != nil {
+		return nil, logFailure(ctx, "detach_tag", modifiedError(err, ifMatch))
+	}
 	return task, nil
 }
 
-func (s *TaskService) GetAllTasks(ctx context.Context) ([]TaskResponse, *rest.RestError) {
-	tasks, err := s.Repository.GetAll(ctx)
+func (s *TaskService) CreateTag(ctx context.Context, req TagRequest) (*TagResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskService.CreateTag")
+	defer span.End()
+
+	if err := rest.ValidationError(req.Validate()); err != nil {
+		return nil, err
+	}
+
+	tag, err := s.Store.CreateTag(ctx, domain.NewTag(req.Name))
 	if err != nil {
+		return nil, logFailure(ctx, "create_tag", err)
+	}
+	return tag, nil
+}
+
+func (s *TaskService) GetTagByID(ctx context.Context, id uuid.UUID) (*TagResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskService.GetTagByID")
+	defer span.End()
+
+	tag, err := s.Store.GetTag(ctx, id)
+	if err != nil {
+		return nil, logFailure(ctx, "get_tag", err)
+	}
+	return tag, nil
+}
+
+func (s *TaskService) GetAllTags(ctx context.Context) ([]TagResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskService.GetAllTags")
+	defer span.End()
+
+	tags, err := s.Store.GetTags(ctx)
+	if err != nil {
+		return nil, logFailure(ctx, "list_tags", err)
+	}
+	return tags, nil
+}
+
+func (s *TaskService) RenameTag(ctx context.Context, id uuid.UUID, req TagRequest) (*TagResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskService.RenameTag")
+	defer span.End()
+
+	if err := rest.ValidationError(req.Validate()); err != nil {
 		return nil, err
 	}
-	return tasks, nil
+
+	tag, _, err := s.Store.RenameTag(ctx, id, domain.NormalizeTagName(req.Name))
+	if err != nil {
+		return nil, logFailure(ctx, "rename_tag", err)
+	}
+	return tag, nil
+}
+
+func (s *TaskService) DeleteTag(ctx context.Context, id uuid.UUID) *rest.RestError {
+	ctx, span := tracing.Tracer().Start(ctx, "TaskService.DeleteTag")
+	defer span.End()
+
+	if _, err := s.Store.DeleteTag(ctx, id); err != nil {
+		return logFailure(ctx, "delete_tag", err)
+	}
+	return nil
+}
+
+func sameDueAt(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func logFailure(ctx context.Context, operation string, err *rest.RestError) *rest.RestError {
+	if err.Code >= http.StatusInternalServerError {
+		trace.SpanFromContext(ctx).SetStatus(codes.Error, err.Message)
+		log.FromContext(ctx).Error("Task operation failed",
+			slog.String("operation", operation),
+			slog.Int("code", err.Code),
+			slog.String("error", err.Message))
+	}
+	return err
 }
@@ -0,0 +1,287 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	domain "github.com/felipeversiane/task-api/internal"
+	"github.com/felipeversiane/task-api/internal/patch"
+	"github.com/felipeversiane/task-api/internal/rest"
+	"github.com/felipeversiane/task-api/internal/stream"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	SocketSubscribe   = "subscribe"
+	SocketUnsubscribe = "unsubscribe"
+	SocketPatch       = "patch"
+	SocketAck         = "ack"
+	SocketError       = "error"
+	SocketEvent       = "event"
+
+	socketSendQueue  = 64
+	socketMaxMessage = 64 << 10
+	socketWriteWait  = 10 * time.Second
+	socketPongWait   = 60 * time.Second
+	socketPingPeriod = socketPongWait * 9 / 10
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+}
+
+type SocketRequest struct {
+	Type       string             `json:"type"`
+	ID         string             `json:"id"`
+	TaskIDs    []uuid.UUID        `json:"task_ids"`
+	Situations []domain.Situation `json:"situations"`
+	TaskID     uuid.UUID          `json:"task_id"`
+	Version    int64              `json:"version"`
+	Changes    json.RawMessage    `json:"changes"`
+}
+
+type SocketMessage struct {
+	Type     string          `json:"type"`
+	ID       string          `json:"id,omitempty"`
+	Event    string          `json:"event,omitempty"`
+	Sequence uint64          `json:"sequence,omitempty"`
+	Task     *TaskResponse   `json:"task,omitempty"`
+	Error    *rest.RestError `json:"error,omitempty"`
+}
+
+type socketFilter struct {
+	mu         sync.Mutex
+	ids        map[uuid.UUID]bool
+	situations map[domain.Situation]bool
+}
+
+func (f *socketFilter) add(ids []uuid.UUID, situations []domain.Situation) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, id := range ids {
+		f.ids[id] = true
+	}
+	for _, situation := range situations {
+		f.situations[situation] = true
+	}
+}
+
+func (f *socketFilter) remove(ids []uuid.UUID, situations []domain.Situation) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(ids) == 0 && len(situations) == 0 {
+		clear(f.ids)
+		clear(f.situations)
+		return
+	}
+	for _, id := range ids {
+		delete(f.ids, id)
+	}
+	for _, situation := range situations {
+		delete(f.situations, situation)
+	}
+}
+
+// matches also reports tasks that left a subscribed situation, so boards can drop them.
+func (f *socketFilter) matches(task StreamTask) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.ids[task.ID] || f.situations[task.Situation] {
+		return true
+	}
+	return task.Before != nil && f.situations[task.Before.Situation]
+}
+
+type socketConn struct {
+	conn      *websocket.Conn
+	service   *TaskService
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+	closeCode int
+	closeText string
+	filter    socketFilter
+}
+
+func (h *TaskHandler) ServeSocket(w http.ResponseWriter, r *http.Request) {
+	if h.Stream == nil {
+		httpErr := rest.NewServiceUnavailableError("task event stream is not available")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(hijacker(w), r, nil)
+	if err != nil {
+		return
+	}
+
+	sub, _, _ := h.Stream.Subscribe(r.Context(), "")
+	defer h.Stream.Unsubscribe(sub)
+
+	c := &socketConn{
+		conn:    conn,
+		service: &h.Service,
+		send:    make(chan []byte, socketSendQueue),
+		done:    make(chan struct{}),
+		filter: socketFilter{
+			ids:        map[uuid.UUID]bool{},
+			situations: map[domain.Situation]bool{},
+		},
+	}
+
+	go c.writeLoop()
+	go c.forward(sub)
+	c.readLoop(r.Context())
+}
+
+func (c *socketConn) readLoop(ctx context.Context) {
+	defer c.close(websocket.CloseNormalClosure, "")
+
+	c.conn.SetReadLimit(socketMaxMessage)
+	c.conn.SetReadDeadline(time.Now().Add(socketPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(socketPongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req SocketRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			c.reply(SocketMessage{Type: SocketError, Error: rest.NewBadRequestError("invalid message payload")})
+			continue
+		}
+		c.reply(c.handle(ctx, req))
+	}
+}
+
+func (c *socketConn) handle(ctx context.Context, req SocketRequest) SocketMessage {
+	switch req.Type {
+	case SocketSubscribe:
+		for _, situation := range req.Situations {
+			if !domain.IsValidSituation(situation) {
+				return socketError(req.ID, rest.NewBadRequestError(fmt.Sprintf("invalid situation %q", situation)))
+			}
+		}
+		c.filter.add(req.TaskIDs, req.Situations)
+		return SocketMessage{Type: SocketAck, ID: req.ID}
+	case SocketUnsubscribe:
+		c.filter.remove(req.TaskIDs, req.Situations)
+		return SocketMessage{Type: SocketAck, ID: req.ID}
+	case SocketPatch:
+		if req.TaskID == uuid.Nil || len(req.Changes) == 0 {
+			return socketError(req.ID, rest.NewBadRequestError("task_id and changes are required"))
+		}
+		var ifMatch string
+		if req.Version != 0 {
+			ifMatch = ETag(&TaskResponse{ID: req.TaskID, Version: req.Version})
+		}
+		task, err := c.service.PatchTask(ctx, req.TaskID, patch.MergePatchContentType, req.Changes, ifMatch)
+		if err != nil {
+			return socketError(req.ID, err)
+		}
+		return SocketMessage{Type: SocketAck, ID: req.ID, Task: task}
+	default:
+		return socketError(req.ID, rest.NewBadRequestError(fmt.Sprintf("unknown message type %q", req.Type)))
+	}
+}
+
+func (c *socketConn) forward(sub *stream.Subscriber) {
+	for {
+		select {
+		case <-c.done:
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				c.close(websocket.CloseGoingAway, "event stream closed")
+				return
+			}
+
+			var task StreamTask
+			if err := json.Unmarshal(event.Data, &task); err != nil || task.TaskResponse == nil || !c.filter.matches(task) {
+				continue
+			}
+
+			payload, _ := json.Marshal(SocketMessage{Type: SocketEvent, Event: event.Type, Sequence: event.ID, Task: task.TaskResponse})
+			select {
+			case c.send <- payload:
+			default:
+				c.close(websocket.CloseTryAgainLater, "send queue is full")
+				return
+			}
+		}
+	}
+}
+
+func (c *socketConn) writeLoop() {
+	ping := time.NewTicker(socketPingPeriod)
+	defer func() {
+		ping.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case payload := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(socketWriteWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ping.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(socketWriteWait)); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-c.done:
+			message := websocket.FormatCloseMessage(c.closeCode, c.closeText)
+			c.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(socketWriteWait))
+			return
+		}
+	}
+}
+
+func (c *socketConn) reply(message SocketMessage) {
+	payload, _ := json.Marshal(message)
+	select {
+	case c.send <- payload:
+	case <-c.done:
+	}
+}
+
+func (c *socketConn) close(code int, text string) {
+	c.closeOnce.Do(func() {
+		c.closeCode, c.closeText = code, text
+		close(c.done)
+	})
+}
+
+func socketError(id string, err *rest.RestError) SocketMessage {
+	return SocketMessage{Type: SocketError, ID: id, Error: err}
+}
+
+// hijacker unwraps middleware response writers, which do not implement http.Hijacker themselves.
+func hijacker(w http.ResponseWriter) http.ResponseWriter {
+	for {
+		if _, ok := w.(http.Hijacker); ok {
+			return w
+		}
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return w
+		}
+		w = unwrapper.Unwrap()
+	}
+}
@@ -0,0 +1,38 @@
+package task
+
+import (
+	"context"
+
+	domain "github.com/felipeversiane/task-api/internal"
+	"github.com/felipeversiane/task-api/internal/rest"
+	"github.com/google/uuid"
+)
+
+type TaskStore interface {
+	Insert(ctx context.Context, task domain.Task) (*TaskResponse, *rest.RestError)
+	Update(ctx context.Context, id uuid.UUID, current *TaskResponse, task domain.Task) (*TaskResponse, *rest.RestError)
+	Patch(ctx context.Context, id uuid.UUID, current *TaskResponse, task domain.Task, fields []string) (*TaskResponse, *rest.RestError)
+	Transition(ctx context.Context, current *TaskResponse, transition domain.Transition) (*TaskResponse, *rest.RestError)
+	GetTransitions(ctx context.Context, id uuid.UUID) ([]TransitionResponse, *rest.RestError)
+	Delete(ctx context.Context, id uuid.UUID, version int64) *rest.RestError
+	GetByID(ctx context.Context, id uuid.UUID) (*TaskResponse, *rest.RestError)
+	GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]TaskResponse, *rest.RestError)
+	Batch(ctx context.Context, ops []BatchOperation, atomic bool) ([]BatchResult, *rest.RestError)
+	GetAll(ctx context.Context, query TaskListQuery) ([]TaskResponse, *rest.RestError)
+	Search(ctx context.Context, query SearchQuery) ([]TaskSearchResult, *rest.RestError)
+	AttachTags(ctx context.Context, current *TaskResponse, names []string) (*TaskResponse, *rest.RestError)
+	DetachTag(ctx context.Context, current *TaskResponse, name string) (*TaskResponse, *rest.RestError)
+	CreateTag(ctx context.Context, tag domain.Tag) (*TagResponse, *rest.RestError)
+	GetTag(ctx context.Context, id uuid.UUID) (*TagResponse, *rest.RestError)
+	GetTags(ctx context.Context) ([]TagResponse, *rest.RestError)
+	RenameTag(ctx context.Context, id uuid.UUID, name string) (*TagResponse, []TaskChange, *rest.RestError)
+	DeleteTag(ctx context.Context, id uuid.UUID) ([]TaskChange, *rest.RestError)
+}
+
+var updatableFields = []string{"name", "description", "situation", "priority", "due_at"}
+
+var (
+	_ TaskStore = (*TaskRepository)(nil)
+	_ TaskStore = (*PostgresStore)(nil)
+	_ TaskStore = (*MemoryStore)(nil)
+)
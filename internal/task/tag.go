@@ -0,0 +1,80 @@
+package task
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	domain "github.com/felipeversiane/task-api/internal"
+	"github.com/google/uuid"
+)
+
+const (
+	TagMatchAny = "any"
+	TagMatchAll = "all"
+
+	maxAttachedTags = 20
+)
+
+type TagRequest struct {
+	Name string `json:"name"`
+}
+
+type AttachTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+type TagResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (req *TagRequest) Validate() error {
+	return domain.ValidateTagName("name", domain.NormalizeTagName(req.Name))
+}
+
+func (req *AttachTagsRequest) Validate() error {
+	var errs domain.ValidationErrors
+	if len(req.Tags) == 0 {
+		return errs.Add("tags", domain.CodeRequired, "tags cannot be empty").Err()
+	}
+	if len(req.Tags) > maxAttachedTags {
+		return errs.Add("tags", domain.CodeTooLong, fmt.Sprintf("at most %d tags can be attached at once", maxAttachedTags)).Err()
+	}
+	for _, name := range req.Tags {
+		if err := domain.ValidateTagName("tags", domain.NormalizeTagName(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (req *AttachTagsRequest) Names() []string {
+	names := make([]string, 0, len(req.Tags))
+	for _, name := range req.Tags {
+		names = append(names, domain.NormalizeTagName(name))
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+func DomainToResponseTag(tag domain.Tag) TagResponse {
+	return TagResponse{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		CreatedAt: tag.CreatedAt,
+	}
+}
+
+func withTags(tags []string, added ...string) []string {
+	merged := append(append([]string{}, tags...), added...)
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
+
+func withoutTag(tags []string, removed string) []string {
+	return slices.DeleteFunc(append([]string{}, tags...), func(name string) bool {
+		return name == removed
+	})
+}
@@ -0,0 +1,33 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/felipeversiane/task-api/internal/config"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewExporter_ShouldPostSpansToTracesPath(t *testing.T) {
+	paths := make(chan string, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths <- r.URL.Path
+	}))
+	defer collector.Close()
+
+	ctx := context.Background()
+	exporter, _, err := newExporter(ctx, config.TracingConfig{Exporter: "otlp", Endpoint: collector.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exporter.Shutdown(ctx)
+
+	if err := exporter.ExportSpans(ctx, tracetest.SpanStubs{{Name: "span"}}.Snapshots()); err != nil {
+		t.Fatal(err)
+	}
+	if path := <-paths; path != "/v1/traces" {
+		t.Fatalf("Expected spans to be posted to /v1/traces, got %s", path)
+	}
+}
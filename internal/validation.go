@@ -0,0 +1,43 @@
+package domain
+
+import "strings"
+
+const (
+	CodeRequired      = "required"
+	CodeTooShort      = "too_short"
+	CodeTooLong       = "too_long"
+	CodeInvalidEnum   = "invalid_enum"
+	CodeInvalidURL    = "invalid_url"
+	CodeInvalidFormat = "invalid_format"
+)
+
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Message
+}
+
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e ValidationErrors) Add(field, code, message string) ValidationErrors {
+	return append(e, FieldError{Field: field, Code: code, Message: message})
+}
+
+func (e ValidationErrors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,187 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	domain "github.com/felipeversiane/task-api/internal"
+	"github.com/felipeversiane/task-api/internal/outbox"
+	"github.com/google/uuid"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryDead      = "dead"
+
+	AllEvents = "*"
+)
+
+var knownEvents = []string{AllEvents, outbox.TaskCreated, outbox.TaskUpdated, outbox.TaskDeleted}
+
+type Subscription struct {
+	ID        uuid.UUID
+	URL       string
+	Events    []string
+	Secret    string
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Delivery struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	EventID        uuid.UUID
+	EventType      string
+	Payload        json.RawMessage
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	URL            string
+	Secret         string
+	LeasedUntil    time.Time
+}
+
+type EventMessage struct {
+	ID        uuid.UUID   `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+type SubscriptionRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+	Active *bool    `json:"active"`
+}
+
+type SubscriptionResponse struct {
+	ID        uuid.UUID `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type DeliveryResponse struct {
+	ID             uuid.UUID `json:"id"`
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	EventID        uuid.UUID `json:"event_id"`
+	EventType      string    `json:"event_type"`
+	Status         string    `json:"status"`
+	Attempts       int       `json:"attempts"`
+	NextAttemptAt  time.Time `json:"next_attempt_at"`
+	LastStatusCode int       `json:"last_status_code,omitempty"`
+	LastError      string    `json:"last_error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (req *SubscriptionRequest) Validate() error {
+	var errs domain.ValidationErrors
+
+	target, err := url.Parse(req.URL)
+	switch {
+	case req.URL == "":
+		errs = errs.Add("url", domain.CodeRequired, "url is required")
+	case err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "":
+		errs = errs.Add("url", domain.CodeInvalidURL, "url must be an absolute http or https URL")
+	}
+
+	for _, event := range req.Events {
+		if !slices.Contains(knownEvents, event) {
+			errs = errs.Add("events", domain.CodeInvalidEnum, fmt.Sprintf("unknown event %q", event))
+			break
+		}
+	}
+
+	if len(req.Secret) > 0 && len(req.Secret) < 16 {
+		errs = errs.Add("secret", domain.CodeTooShort, "secret must be at least 16 characters long")
+	}
+
+	return errs.Err()
+}
+
+func (s *Subscription) Matches(eventType string) bool {
+	return slices.Contains(s.Events, AllEvents) || slices.Contains(s.Events, eventType)
+}
+
+func RequestToSubscription(req SubscriptionRequest) (Subscription, error) {
+	events := req.Events
+	if len(events) == 0 {
+		events = []string{AllEvents}
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		generated, err := generateSecret()
+		if err != nil {
+			return Subscription{}, err
+		}
+		secret = generated
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	now := time.Now()
+	return Subscription{
+		ID:        uuid.New(),
+		URL:       req.URL,
+		Events:    events,
+		Secret:    secret,
+		Active:    active,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+func SubscriptionToResponse(sub Subscription) SubscriptionResponse {
+	return SubscriptionResponse{
+		ID:        sub.ID,
+		URL:       sub.URL,
+		Events:    sub.Events,
+		Active:    sub.Active,
+		CreatedAt: sub.CreatedAt,
+		UpdatedAt: sub.UpdatedAt,
+	}
+}
+
+func DeliveryToResponse(delivery Delivery) DeliveryResponse {
+	return DeliveryResponse{
+		ID:             delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		UpdatedAt:      delivery.UpdatedAt,
+	}
+}
+
+func generateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,150 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/felipeversiane/task-api/internal/rest"
+	"github.com/felipeversiane/task-api/internal/tracing"
+)
+
+type WebhookHandler struct {
+	Service Service
+}
+
+func NewWebhookHandler(service Service) WebhookHandler {
+	return WebhookHandler{
+		Service: service,
+	}
+}
+
+func (h *WebhookHandler) PostWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "WebhookHandler.PostWebhook")
+	defer span.End()
+
+	var req SubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpErr := rest.NewBadRequestError("invalid request payload")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	resp, err := h.Service.CreateSubscription(ctx, req)
+	if err != nil {
+		rest.RespondWithError(w, r, err)
+		return
+	}
+
+	rest.RespondWithJSON(w, http.StatusCreated, resp)
+}
+
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "WebhookHandler.UpdateWebhook")
+	defer span.End()
+
+	id, parseErr := rest.ExtractIDFromPath(r)
+	if parseErr != nil {
+		httpErr := rest.NewBadRequestError("invalid webhook ID")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	var req SubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpErr := rest.NewBadRequestError("invalid request payload")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	resp, err := h.Service.UpdateSubscription(ctx, id, req)
+	if err != nil {
+		rest.RespondWithError(w, r, err)
+		return
+	}
+
+	rest.RespondWithJSON(w, http.StatusOK, resp)
+}
+
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "WebhookHandler.DeleteWebhook")
+	defer span.End()
+
+	id, parseErr := rest.ExtractIDFromPath(r)
+	if parseErr != nil {
+		httpErr := rest.NewBadRequestError("invalid webhook ID")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	if err := h.Service.DeleteSubscription(ctx, id); err != nil {
+		rest.RespondWithError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) GetWebhookByID(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "WebhookHandler.GetWebhookByID")
+	defer span.End()
+
+	id, parseErr := rest.ExtractIDFromPath(r)
+	if parseErr != nil {
+		httpErr := rest.NewBadRequestError("invalid webhook ID")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	resp, err := h.Service.GetSubscription(ctx, id)
+	if err != nil {
+		rest.RespondWithError(w, r, err)
+		return
+	}
+
+	rest.RespondWithJSON(w, http.StatusOK, resp)
+}
+
+func (h *WebhookHandler) GetAllWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "WebhookHandler.GetAllWebhooks")
+	defer span.End()
+
+	resp, err := h.Service.ListSubscriptions(ctx)
+	if err != nil {
+		rest.RespondWithError(w, r, err)
+		return
+	}
+
+	rest.RespondWithJSON(w, http.StatusOK, resp)
+}
+
+func (h *WebhookHandler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "WebhookHandler.GetWebhookDeliveries")
+	defer span.End()
+
+	id, parseErr := rest.ExtractIDFromPath(r)
+	if parseErr != nil {
+		httpErr := rest.NewBadRequestError("invalid webhook ID")
+		rest.RespondWithError(w, r, httpErr)
+		return
+	}
+
+	var limit int
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			httpErr := rest.NewBadRequestError("limit must be a positive integer")
+			rest.RespondWithError(w, r, httpErr)
+			return
+		}
+		limit = parsed
+	}
+
+	resp, err := h.Service.ListDeliveries(ctx, id, limit)
+	if err != nil {
+		rest.RespondWithError(w, r, err)
+		return
+	}
+
+	rest.RespondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,179 @@
+package webhook
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/felipeversiane/task-api/internal/rest"
+	"github.com/google/uuid"
+)
+
+type MemoryStore struct {
+	mu            sync.Mutex
+	subscriptions map[uuid.UUID]Subscription
+	deliveries    map[uuid.UUID]Delivery
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		subscriptions: map[uuid.UUID]Subscription{},
+		deliveries:    map[uuid.UUID]Delivery{},
+	}
+}
+
+func (m *MemoryStore) CreateSubscription(ctx context.Context, sub Subscription) *rest.RestError {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.subscriptions[sub.ID] = sub
+	return nil
+}
+
+func (m *MemoryStore) UpdateSubscription(ctx context.Context, sub Subscription) *rest.RestError {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.subscriptions[sub.ID]; !ok {
+		return subscriptionNotFound(sub.ID)
+	}
+	m.subscriptions[sub.ID] = sub
+	return nil
+}
+
+func (m *MemoryStore) DeleteSubscription(ctx context.Context, id uuid.UUID) *rest.RestError {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.subscriptions[id]; !ok {
+		return subscriptionNotFound(id)
+	}
+	delete(m.subscriptions, id)
+	for deliveryID, delivery := range m.deliveries {
+		if delivery.SubscriptionID == id {
+			delete(m.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) GetSubscription(ctx context.Context, id uuid.UUID) (*Subscription, *rest.RestError) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sub, ok := m.subscriptions[id]
+	if !ok {
+		return nil, subscriptionNotFound(id)
+	}
+	return &sub, nil
+}
+
+func (m *MemoryStore) ListSubscriptions(ctx context.Context) ([]Subscription, *rest.RestError) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	subs := []Subscription{}
+	for _, sub := range m.subscriptions {
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		return subs[i].CreatedAt.Before(subs[j].CreatedAt)
+	})
+	return subs, nil
+}
+
+func (m *MemoryStore) MatchingSubscriptions(ctx context.Context, eventType string) ([]Subscription, *rest.RestError) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	subs := []Subscription{}
+	for _, sub := range m.subscriptions {
+		if sub.Active && sub.Matches(eventType) {
+			subs = append(subs, sub)
+		}
+	}
+	return subs, nil
+}
+
+func (m *MemoryStore) EnqueueDeliveries(ctx context.Context, deliveries []Delivery) *rest.RestError {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	enqueued := map[[2]uuid.UUID]bool{}
+	for _, delivery := range m.deliveries {
+		enqueued[[2]uuid.UUID{delivery.SubscriptionID, delivery.EventID}] = true
+	}
+	for _, delivery := range deliveries {
+		key := [2]uuid.UUID{delivery.SubscriptionID, delivery.EventID}
+		if enqueued[key] {
+			continue
+		}
+		enqueued[key] = true
+		m.deliveries[delivery.ID] = delivery
+	}
+	return nil
+}
+
+func (m *MemoryStore) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Delivery, *rest.RestError) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	due := []Delivery{}
+	for _, delivery := range m.deliveries {
+		if delivery.Status == DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	for i := range due {
+		due[i].NextAttemptAt = now.Add(lease)
+		due[i].LeasedUntil = due[i].NextAttemptAt
+		m.deliveries[due[i].ID] = due[i]
+
+		sub := m.subscriptions[due[i].SubscriptionID]
+		due[i].URL, due[i].Secret = sub.URL, sub.Secret
+	}
+	return due, nil
+}
+
+func (m *MemoryStore) SaveAttempt(ctx context.Context, delivery Delivery) *rest.RestError {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.deliveries[delivery.ID]
+	if !ok || stored.Status != DeliveryPending || !stored.NextAttemptAt.Equal(delivery.LeasedUntil) {
+		return leaseLost(delivery.ID)
+	}
+
+	delivery.URL, delivery.Secret = "", ""
+	delivery.LeasedUntil = time.Time{}
+	m.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func (m *MemoryStore) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]Delivery, *rest.RestError) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deliveries := []Delivery{}
+	for _, delivery := range m.deliveries {
+		if delivery.SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	domain "github.com/felipeversiane/task-api/internal"
+)
+
+var errNonPublicDestination = errors.New("webhook destination is not a public address")
+
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func destinationError(ctx context.Context, resolver *net.Resolver, rawURL string) error {
+	var errs domain.ValidationErrors
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+
+	host := strings.ToLower(target.Hostname())
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !isPublicAddr(addr) {
+			return errs.Add("url", domain.CodeInvalidURL, "url must point to a public address").Err()
+		}
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || !strings.Contains(host, ".") {
+		return errs.Add("url", domain.CodeInvalidURL, "url must point to a public host").Err()
+	}
+
+	addrs, err := resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return errs.Add("url", domain.CodeInvalidURL, fmt.Sprintf("url host %s could not be resolved", host)).Err()
+	}
+	for _, addr := range addrs {
+		if !isPublicAddr(addr) {
+			return errs.Add("url", domain.CodeInvalidURL, "url must point to a public address").Err()
+		}
+	}
+	return nil
+}
+
+func newClient(timeout time.Duration, allowPrivateNetworks bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivateNetworks {
+		dialer.Control = publicOnly
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+func publicOnly(network string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !isPublicAddr(addrPort.Addr()) {
+		return errNonPublicDestination
+	}
+	return nil
+}
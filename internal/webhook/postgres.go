@@ -0,0 +1,229 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/felipeversiane/task-api/internal/database"
+	"github.com/felipeversiane/task-api/internal/rest"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	subscriptionColumns = `id, url, events, secret, active, created_at, updated_at`
+	deliveryColumns     = `id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+	                       last_status_code, last_error, created_at, updated_at`
+)
+
+type PostgresStore struct {
+	Database *pgxpool.Pool
+}
+
+func NewPostgresStore(database *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{
+		Database: database,
+	}
+}
+
+func (r *PostgresStore) CreateSubscription(ctx context.Context, sub Subscription) *rest.RestError {
+	query := `INSERT INTO webhook_subscriptions (` + subscriptionColumns + `)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := r.Database.Exec(ctx, query, sub.ID, sub.URL, sub.Events, sub.Secret, sub.Active, sub.CreatedAt, sub.UpdatedAt)
+	if err != nil {
+		return database.MapError(err)
+	}
+	return nil
+}
+
+func (r *PostgresStore) UpdateSubscription(ctx context.Context, sub Subscription) *rest.RestError {
+	query := `UPDATE webhook_subscriptions SET url = $2, events = $3, secret = $4, active = $5, updated_at = $6
+	          WHERE id = $1`
+
+	tag, err := r.Database.Exec(ctx, query, sub.ID, sub.URL, sub.Events, sub.Secret, sub.Active, sub.UpdatedAt)
+	if err != nil {
+		return database.MapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return subscriptionNotFound(sub.ID)
+	}
+	return nil
+}
+
+func (r *PostgresStore) DeleteSubscription(ctx context.Context, id uuid.UUID) *rest.RestError {
+	tag, err := r.Database.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return database.MapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return subscriptionNotFound(id)
+	}
+	return nil
+}
+
+func (r *PostgresStore) GetSubscription(ctx context.Context, id uuid.UUID) (*Subscription, *rest.RestError) {
+	query := `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`
+	sub, err := scanSubscription(r.Database.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, subscriptionNotFound(id)
+		}
+		return nil, database.MapError(err)
+	}
+	return &sub, nil
+}
+
+func (r *PostgresStore) ListSubscriptions(ctx context.Context) ([]Subscription, *rest.RestError) {
+	query := `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions ORDER BY created_at`
+	return r.querySubscriptions(ctx, query)
+}
+
+func (r *PostgresStore) MatchingSubscriptions(ctx context.Context, eventType string) ([]Subscription, *rest.RestError) {
+	query := `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions
+	          WHERE active AND ($1 = ANY(events) OR '*' = ANY(events))`
+	return r.querySubscriptions(ctx, query, eventType)
+}
+
+func (r *PostgresStore) querySubscriptions(ctx context.Context, query string, args ...interface{}) ([]Subscription, *rest.RestError) {
+	rows, err := r.Database.Query(ctx, query, args...)
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	defer rows.Close()
+
+	subs := []Subscription{}
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, database.MapError(err)
+		}
+		subs = append(subs, sub)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, database.MapError(err)
+	}
+	return subs, nil
+}
+
+func (r *PostgresStore) EnqueueDeliveries(ctx context.Context, deliveries []Delivery) *rest.RestError {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, d := range deliveries {
+		batch.Queue(`INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload, status, attempts,
+		                                             next_attempt_at, last_status_code, last_error, created_at, updated_at)
+		             VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		             ON CONFLICT (subscription_id, event_id) DO NOTHING`,
+			d.ID, d.SubscriptionID, d.EventID, d.EventType, d.Payload, d.Status,
+			d.Attempts, d.NextAttemptAt, d.LastStatusCode, d.LastError, d.CreatedAt, d.UpdatedAt)
+	}
+	if err := r.Database.SendBatch(ctx, batch).Close(); err != nil {
+		return database.MapError(err)
+	}
+	return nil
+}
+
+func (r *PostgresStore) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Delivery, *rest.RestError) {
+	query := `WITH claimed AS (
+	              UPDATE webhook_deliveries SET next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+	              WHERE id IN (
+	                  SELECT id FROM webhook_deliveries
+	                  WHERE status = 'pending' AND next_attempt_at <= NOW()
+	                  ORDER BY next_attempt_at
+	                  LIMIT $1
+	                  FOR UPDATE SKIP LOCKED
+	              )
+	              RETURNING ` + deliveryColumns + `
+	          )
+	          SELECT claimed.id, claimed.subscription_id, claimed.event_id, claimed.event_type, claimed.payload,
+	                 claimed.status, claimed.attempts, claimed.next_attempt_at, claimed.last_status_code,
+	                 claimed.last_error, claimed.created_at, claimed.updated_at, s.url, s.secret
+	          FROM claimed JOIN webhook_subscriptions s ON s.id = claimed.subscription_id`
+
+	rows, err := r.Database.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	defer rows.Close()
+
+	deliveries := []Delivery{}
+	for rows.Next() {
+		var d Delivery
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Status,
+			&d.Attempts, &d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.UpdatedAt,
+			&d.URL, &d.Secret); err != nil {
+			return nil, database.MapError(err)
+		}
+		d.LeasedUntil = d.NextAttemptAt
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, database.MapError(err)
+	}
+	return deliveries, nil
+}
+
+func (r *PostgresStore) SaveAttempt(ctx context.Context, d Delivery) *rest.RestError {
+	query := `UPDATE webhook_deliveries
+	          SET status = $2, attempts = $3, next_attempt_at = $4, last_status_code = $5, last_error = $6, updated_at = $7
+	          WHERE id = $1 AND status = 'pending' AND next_attempt_at = $8`
+
+	tag, err := r.Database.Exec(ctx, query, d.ID, d.Status, d.Attempts, d.NextAttemptAt, d.LastStatusCode, d.LastError, d.UpdatedAt, d.LeasedUntil)
+	if err != nil {
+		return database.MapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return leaseLost(d.ID)
+	}
+	return nil
+}
+
+func (r *PostgresStore) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]Delivery, *rest.RestError) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries
+	          WHERE subscription_id = $1
+	          ORDER BY created_at DESC
+	          LIMIT $2`
+
+	rows, err := r.Database.Query(ctx, query, subscriptionID, limit)
+	if err != nil {
+		return nil, database.MapError(err)
+	}
+	defer rows.Close()
+
+	deliveries := []Delivery{}
+	for rows.Next() {
+		var d Delivery
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Status,
+			&d.Attempts, &d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, database.MapError(err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, database.MapError(err)
+	}
+	return deliveries, nil
+}
+
+func scanSubscription(row pgx.Row) (Subscription, error) {
+	var sub Subscription
+	err := row.Scan(&sub.ID, &sub.URL, &sub.Events, &sub.Secret, &sub.Active, &sub.CreatedAt, &sub.UpdatedAt)
+	return sub, err
+}
+
+func leaseLost(id uuid.UUID) *rest.RestError {
+	return rest.NewConflictError(fmt.Sprintf("webhook delivery %s is no longer leased by this worker", id))
+}
+
+func subscriptionNotFound(id uuid.UUID) *rest.RestError {
+	return rest.NewNotFoundError(fmt.Sprintf("webhook with ID %s not found", id))
+}
@@ -0,0 +1,26 @@
+package webhook
+
+import (
+	"net/http"
+
+	"github.com/felipeversiane/task-api/internal/config"
+	"github.com/felipeversiane/task-api/internal/database"
+)
+
+var Handler WebhookHandler
+
+func WebhooksRouter(mux *http.ServeMux, cfg config.WebhookConfig) {
+	service := NewService(NewPostgresStore(database.Connection))
+	service.AllowPrivateNetworks = cfg.AllowPrivateNetworks
+	Handler = NewWebhookHandler(service)
+	RegisterRoutes(mux, &Handler)
+}
+
+func RegisterRoutes(mux *http.ServeMux, handler *WebhookHandler) {
+	mux.HandleFunc("POST /api/v1/webhooks", handler.PostWebhook)
+	mux.HandleFunc("GET /api/v1/webhooks", handler.GetAllWebhooks)
+	mux.HandleFunc("GET /api/v1/webhooks/{id}", handler.GetWebhookByID)
+	mux.HandleFunc("PUT /api/v1/webhooks/{id}", handler.UpdateWebhook)
+	mux.HandleFunc("DELETE /api/v1/webhooks/{id}", handler.DeleteWebhook)
+	mux.HandleFunc("GET /api/v1/webhooks/{id}/deliveries", handler.GetWebhookDeliveries)
+}
@@ -0,0 +1,203 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/felipeversiane/task-api/internal/log"
+	"github.com/felipeversiane/task-api/internal/outbox"
+	"github.com/felipeversiane/task-api/internal/rest"
+	"github.com/felipeversiane/task-api/internal/tracing"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 100
+)
+
+type Service struct {
+	Store                Store
+	Resolver             *net.Resolver
+	AllowPrivateNetworks bool
+}
+
+func NewService(store Store) Service {
+	return Service{
+		Store:    store,
+		Resolver: net.DefaultResolver,
+	}
+}
+
+func (s *Service) CreateSubscription(ctx context.Context, req SubscriptionRequest) (*SubscriptionResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "WebhookService.CreateSubscription")
+	defer span.End()
+
+	if err := s.validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	sub, genErr := RequestToSubscription(req)
+	if genErr != nil {
+		return nil, rest.NewInternalServerError(genErr.Error())
+	}
+	if err := s.Store.CreateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	resp := SubscriptionToResponse(sub)
+	resp.Secret = sub.Secret
+	return &resp, nil
+}
+
+func (s *Service) UpdateSubscription(ctx context.Context, id uuid.UUID, req SubscriptionRequest) (*SubscriptionResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "WebhookService.UpdateSubscription")
+	defer span.End()
+
+	if err := s.validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	current, err := s.Store.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, genErr := RequestToSubscription(req)
+	if genErr != nil {
+		return nil, rest.NewInternalServerError(genErr.Error())
+	}
+	sub.ID = current.ID
+	sub.CreatedAt = current.CreatedAt
+	if req.Secret == "" {
+		sub.Secret = current.Secret
+	}
+
+	if err := s.Store.UpdateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	resp := SubscriptionToResponse(sub)
+	return &resp, nil
+}
+
+func (s *Service) DeleteSubscription(ctx context.Context, id uuid.UUID) *rest.RestError {
+	ctx, span := tracing.Tracer().Start(ctx, "WebhookService.DeleteSubscription")
+	defer span.End()
+
+	return s.Store.DeleteSubscription(ctx, id)
+}
+
+func (s *Service) GetSubscription(ctx context.Context, id uuid.UUID) (*SubscriptionResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "WebhookService.GetSubscription")
+	defer span.End()
+
+	sub, err := s.Store.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := SubscriptionToResponse(*sub)
+	return &resp, nil
+}
+
+func (s *Service) ListSubscriptions(ctx context.Context) ([]SubscriptionResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "WebhookService.ListSubscriptions")
+	defer span.End()
+
+	subs, err := s.Store.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]SubscriptionResponse, len(subs))
+	for i, sub := range subs {
+		resp[i] = SubscriptionToResponse(sub)
+	}
+	return resp, nil
+}
+
+func (s *Service) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]DeliveryResponse, *rest.RestError) {
+	ctx, span := tracing.Tracer().Start(ctx, "WebhookService.ListDeliveries")
+	defer span.End()
+
+	if limit <= 0 {
+		limit = defaultDeliveryLimit
+	}
+	if limit > maxDeliveryLimit {
+		limit = maxDeliveryLimit
+	}
+
+	if _, err := s.Store.GetSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.Store.ListDeliveries(ctx, subscriptionID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]DeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		resp[i] = DeliveryToResponse(delivery)
+	}
+	return resp, nil
+}
+
+// Publish reuses the outbox event ID so a retried batch is not enqueued twice.
+func (s *Service) Publish(ctx context.Context, events []outbox.Event) (int, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "WebhookService.Publish")
+	defer span.End()
+
+	subsByType := map[string][]Subscription{}
+	var deliveries []Delivery
+	now := time.Now()
+	for _, event := range events {
+		subs, ok := subsByType[event.Type]
+		if !ok {
+			var err *rest.RestError
+			if subs, err = s.Store.MatchingSubscriptions(ctx, event.Type); err != nil {
+				return 0, err
+			}
+			subsByType[event.Type] = subs
+		}
+		if len(subs) == 0 {
+			continue
+		}
+
+		message := EventMessage{ID: event.ID, Type: event.Type, CreatedAt: event.CreatedAt, Data: event.Payload}
+		body, err := json.Marshal(message)
+		if err != nil {
+			return 0, err
+		}
+		for _, sub := range subs {
+			deliveries = append(deliveries, Delivery{
+				ID:             uuid.New(),
+				SubscriptionID: sub.ID,
+				EventID:        event.ID,
+				EventType:      event.Type,
+				Payload:        body,
+				Status:         DeliveryPending,
+				NextAttemptAt:  now,
+				CreatedAt:      now,
+				UpdatedAt:      now,
+			})
+		}
+	}
+
+	if err := s.Store.EnqueueDeliveries(ctx, deliveries); err != nil {
+		log.FromContext(ctx).Error("Failed to enqueue webhook deliveries", slog.String("error", err.Message))
+		return 0, err
+	}
+	return len(events), nil
+}
+
+func (s *Service) validate(ctx context.Context, req SubscriptionRequest) *rest.RestError {
+	if err := rest.ValidationError(req.Validate()); err != nil || s.AllowPrivateNetworks {
+		return err
+	}
+	return rest.ValidationError(destinationError(ctx, s.Resolver, req.URL))
+}
@@ -0,0 +1,27 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/felipeversiane/task-api/internal/rest"
+	"github.com/google/uuid"
+)
+
+type Store interface {
+	CreateSubscription(ctx context.Context, sub Subscription) *rest.RestError
+	UpdateSubscription(ctx context.Context, sub Subscription) *rest.RestError
+	DeleteSubscription(ctx context.Context, id uuid.UUID) *rest.RestError
+	GetSubscription(ctx context.Context, id uuid.UUID) (*Subscription, *rest.RestError)
+	ListSubscriptions(ctx context.Context) ([]Subscription, *rest.RestError)
+	MatchingSubscriptions(ctx context.Context, eventType string) ([]Subscription, *rest.RestError)
+	EnqueueDeliveries(ctx context.Context, deliveries []Delivery) *rest.RestError
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Delivery, *rest.RestError)
+	SaveAttempt(ctx context.Context, delivery Delivery) *rest.RestError
+	ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]Delivery, *rest.RestError)
+}
+
+var (
+	_ Store = (*PostgresStore)(nil)
+	_ Store = (*MemoryStore)(nil)
+)
@@ -0,0 +1,174 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/felipeversiane/task-api/internal/config"
+	"github.com/felipeversiane/task-api/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const maxErrorLength = 512
+
+var deliveryAttempts = promauto.With(metrics.Default).NewCounterVec(prometheus.CounterOpts{
+	Name: "webhook_delivery_attempts_total",
+	Help: "Webhook delivery attempts by resulting status.",
+}, []string{"status"})
+
+type Worker struct {
+	Store          Store
+	Client         *http.Client
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	PollInterval   time.Duration
+	BatchSize      int
+	Concurrency    int
+	Lease          time.Duration
+}
+
+func NewWorker(store Store, cfg config.WebhookConfig) *Worker {
+	return &Worker{
+		Store:          store,
+		Client:         newClient(cfg.Timeout, cfg.AllowPrivateNetworks),
+		MaxAttempts:    cfg.MaxAttempts,
+		InitialBackoff: cfg.InitialBackoff,
+		MaxBackoff:     cfg.MaxBackoff,
+		PollInterval:   cfg.PollInterval,
+		BatchSize:      cfg.BatchSize,
+		Concurrency:    cfg.Concurrency,
+		Lease:          leaseDuration(cfg),
+	}
+}
+
+// leaseDuration covers a batch whose every delivery runs into the timeout.
+func leaseDuration(cfg config.WebhookConfig) time.Duration {
+	rounds := (cfg.BatchSize + cfg.Concurrency - 1) / cfg.Concurrency
+	return time.Duration(rounds+1) * cfg.Timeout
+}
+
+func (w *Worker) Run(ctx context.Context) {
+	slog.Info("Webhook worker started")
+
+	ticker := time.NewTicker(w.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			if w.deliverDue(ctx) < w.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.Info("Webhook worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) deliverDue(ctx context.Context) int {
+	deliveries, err := w.Store.ClaimDueDeliveries(ctx, w.BatchSize, w.Lease)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("Failed to claim webhook deliveries", slog.String("error", err.Message))
+		}
+		return 0
+	}
+
+	slots := make(chan struct{}, w.Concurrency)
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(delivery Delivery) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			delivery = w.deliver(ctx, delivery)
+			if err := w.Store.SaveAttempt(context.WithoutCancel(ctx), delivery); err != nil {
+				slog.Error("Failed to record webhook attempt",
+					slog.String("delivery_id", delivery.ID.String()),
+					slog.String("error", err.Message))
+			}
+		}(delivery)
+	}
+	wg.Wait()
+	return len(deliveries)
+}
+
+func (w *Worker) deliver(ctx context.Context, delivery Delivery) Delivery {
+	delivery.Attempts++
+	delivery.LastStatusCode, delivery.LastError = w.post(ctx, delivery)
+
+	now := time.Now()
+	delivery.UpdatedAt = now
+	switch {
+	case delivery.LastError == "":
+		delivery.Status = DeliverySucceeded
+	case delivery.Attempts >= w.MaxAttempts:
+		delivery.Status = DeliveryDead
+		slog.Warn("Webhook delivery moved to dead letter",
+			slog.String("delivery_id", delivery.ID.String()),
+			slog.String("url", delivery.URL),
+			slog.Int("attempts", delivery.Attempts),
+			slog.String("error", delivery.LastError))
+	default:
+		delivery.NextAttemptAt = now.Add(w.backoff(delivery.Attempts))
+	}
+
+	deliveryAttempts.WithLabelValues(delivery.Status).Inc()
+	return delivery
+}
+
+func (w *Worker) post(ctx context.Context, delivery Delivery) (int, string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err.Error()
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IDHeader, delivery.EventID.String())
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return 0, truncate(err.Error())
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Sprintf("receiver responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, ""
+}
+
+func (w *Worker) backoff(attempts int) time.Duration {
+	delay := w.InitialBackoff
+	for i := 1; i < attempts && delay < w.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, w.MaxBackoff)
+}
+
+func truncate(message string) string {
+	if len(message) > maxErrorLength {
+		return message[:maxErrorLength]
+	}
+	return message
+}
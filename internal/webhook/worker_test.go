@@ -0,0 +1,298 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	domain "github.com/felipeversiane/task-api/internal"
+	"github.com/felipeversiane/task-api/internal/cache"
+	"github.com/felipeversiane/task-api/internal/outbox"
+	"github.com/felipeversiane/task-api/internal/task"
+	"github.com/google/uuid"
+)
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newReceiver(t *testing.T, status int) (*httptest.Server, chan receivedRequest) {
+	t.Helper()
+
+	received := make(chan receivedRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedRequest{header: r.Header.Clone(), body: body}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func publishEvent(t *testing.T, webhooks *Service, eventType string, payload interface{}) outbox.Event {
+	t.Helper()
+
+	event, err := outbox.NewEvent(eventType, uuid.New(), payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := webhooks.Publish(context.Background(), []outbox.Event{event}); err != nil {
+		t.Fatal(err)
+	}
+	return event
+}
+
+func newTestWorker(store Store) *Worker {
+	return &Worker{
+		Store:        store,
+		Client:       http.DefaultClient,
+		MaxAttempts:  3,
+		MaxBackoff:   time.Minute,
+		PollInterval: time.Second,
+		BatchSize:    10,
+		Concurrency:  2,
+		Lease:        time.Minute,
+	}
+}
+
+func TestWorker_ShouldDeliverSignedTaskEvents(t *testing.T) {
+	receiver, received := newReceiver(t, http.StatusNoContent)
+	store := NewMemoryStore()
+	webhooks := NewService(store)
+	webhooks.AllowPrivateNetworks = true
+
+	sub, err := webhooks.CreateSubscription(context.Background(), SubscriptionRequest{
+		URL:    receiver.URL,
+		Events: []string{outbox.TaskCreated},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repository := task.NewTaskRepository(task.NewMemoryStore(), cache.NewLRUCache(100))
+	tasks := task.NewTaskService(&repository)
+	created, err := tasks.CreateTask(context.Background(), task.TaskRequest{
+		Name:        "Webhook Task",
+		Description: "Notify the integration receivers.",
+		Situation:   domain.SituationNotStarted,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	publishEvent(t, &webhooks, outbox.TaskCreated, task.TaskChange{After: created})
+
+	if delivered := newTestWorker(store).deliverDue(context.Background()); delivered != 1 {
+		t.Fatalf("Expected 1 delivery and received %d", delivered)
+	}
+
+	req := <-received
+	timestamp, _ := strconv.ParseInt(req.header.Get(TimestampHeader), 10, 64)
+	if !Verify(sub.Secret, timestamp, req.body, req.header.Get(SignatureHeader)) {
+		t.Fatalf("Expected a valid signature and received %q", req.header.Get(SignatureHeader))
+	}
+	if req.header.Get(EventHeader) != outbox.TaskCreated {
+		t.Fatalf("Expected event header %q and received %q", outbox.TaskCreated, req.header.Get(EventHeader))
+	}
+
+	var message struct {
+		Type string          `json:"type"`
+		Data task.TaskChange `json:"data"`
+	}
+	if err := json.Unmarshal(req.body, &message); err != nil {
+		t.Fatal(err)
+	}
+	if message.Data.After == nil || message.Data.After.ID != created.ID {
+		t.Fatalf("Expected created task in payload and received %s", req.body)
+	}
+
+	deliveries, _ := webhooks.ListDeliveries(context.Background(), sub.ID, 0)
+	if len(deliveries) != 1 || deliveries[0].Status != DeliverySucceeded || deliveries[0].Attempts != 1 {
+		t.Fatalf("Expected one succeeded delivery and received %+v", deliveries)
+	}
+}
+
+func TestWorker_ShouldDeadLetterAfterMaxAttempts(t *testing.T) {
+	receiver, received := newReceiver(t, http.StatusInternalServerError)
+	store := NewMemoryStore()
+	webhooks := NewService(store)
+	webhooks.AllowPrivateNetworks = true
+
+	sub, err := webhooks.CreateSubscription(context.Background(), SubscriptionRequest{URL: receiver.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	publishEvent(t, &webhooks, outbox.TaskDeleted, map[string]string{})
+
+	worker := newTestWorker(store)
+	for attempt := 1; attempt <= worker.MaxAttempts; attempt++ {
+		if delivered := worker.deliverDue(context.Background()); delivered != 1 {
+			t.Fatalf("Expected attempt %d to be delivered and received %d", attempt, delivered)
+		}
+		<-received
+	}
+
+	if delivered := worker.deliverDue(context.Background()); delivered != 0 {
+		t.Fatalf("Expected no further attempts and received %d", delivered)
+	}
+
+	deliveries, _ := webhooks.ListDeliveries(context.Background(), sub.ID, 0)
+	if len(deliveries) != 1 {
+		t.Fatalf("Expected one delivery and received %d", len(deliveries))
+	}
+	if deliveries[0].Status != DeliveryDead || deliveries[0].Attempts != worker.MaxAttempts {
+		t.Fatalf("Expected dead delivery after %d attempts and received %+v", worker.MaxAttempts, deliveries[0])
+	}
+	if deliveries[0].LastStatusCode != http.StatusInternalServerError {
+		t.Fatalf("Expected last status code 500 and received %d", deliveries[0].LastStatusCode)
+	}
+}
+
+func TestWorker_ShouldNotWaitForSlowReceiverBeforeDeliveringOthers(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(slow.Close)
+	releaseSlow := sync.OnceFunc(func() { close(release) })
+	t.Cleanup(releaseSlow)
+	fast, received := newReceiver(t, http.StatusNoContent)
+
+	store := NewMemoryStore()
+	webhooks := NewService(store)
+	webhooks.AllowPrivateNetworks = true
+	subscriptions := []SubscriptionRequest{
+		{URL: slow.URL, Events: []string{outbox.TaskDeleted}},
+		{URL: fast.URL, Events: []string{outbox.TaskCreated}},
+	}
+	for _, req := range subscriptions {
+		if _, err := webhooks.CreateSubscription(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+		publishEvent(t, &webhooks, req.Events[0], map[string]string{})
+	}
+
+	done := make(chan int)
+	go func() { done <- newTestWorker(store).deliverDue(context.Background()) }()
+
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the fast receiver to be delivered while the slow one is pending")
+	}
+	releaseSlow()
+	if delivered := <-done; delivered != 2 {
+		t.Fatalf("Expected 2 deliveries and received %d", delivered)
+	}
+}
+
+func TestPublish_ShouldEnqueueRelayedEventOnce(t *testing.T) {
+	store := NewMemoryStore()
+	webhooks := NewService(store)
+	webhooks.AllowPrivateNetworks = true
+
+	sub, err := webhooks.CreateSubscription(context.Background(), SubscriptionRequest{URL: "https://example.com/hooks"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := publishEvent(t, &webhooks, outbox.TaskDeleted, map[string]string{})
+	if _, err := webhooks.Publish(context.Background(), []outbox.Event{event}); err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries, _ := webhooks.ListDeliveries(context.Background(), sub.ID, 0)
+	if len(deliveries) != 1 || deliveries[0].EventID != event.ID {
+		t.Fatalf("Expected one delivery for event %s and received %+v", event.ID, deliveries)
+	}
+}
+
+func TestWorker_Backoff_ShouldDoubleUntilMax(t *testing.T) {
+	worker := &Worker{InitialBackoff: 10 * time.Second, MaxBackoff: time.Minute}
+
+	expected := []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, time.Minute, time.Minute}
+	for i, want := range expected {
+		if got := worker.backoff(i + 1); got != want {
+			t.Fatalf("Expected backoff %s after %d attempts and received %s", want, i+1, got)
+		}
+	}
+}
+
+func TestSaveAttempt_ShouldRejectStaleLeaseOwner(t *testing.T) {
+	store := NewMemoryStore()
+	webhooks := NewService(store)
+	webhooks.AllowPrivateNetworks = true
+
+	if _, err := webhooks.CreateSubscription(context.Background(), SubscriptionRequest{URL: "https://example.com/hooks"}); err != nil {
+		t.Fatal(err)
+	}
+	publishEvent(t, &webhooks, outbox.TaskDeleted, map[string]string{})
+
+	expired, _ := store.ClaimDueDeliveries(context.Background(), 1, -time.Second)
+	current, _ := store.ClaimDueDeliveries(context.Background(), 1, time.Minute)
+	if len(expired) != 1 || len(current) != 1 {
+		t.Fatalf("Expected the expired lease to be claimed again and received %d and %d", len(expired), len(current))
+	}
+
+	expired[0].Status = DeliveryDead
+	if err := store.SaveAttempt(context.Background(), expired[0]); err == nil || err.Code != http.StatusConflict {
+		t.Fatalf("Expected the stale owner to be rejected and received %v", err)
+	}
+
+	current[0].Status = DeliverySucceeded
+	if err := store.SaveAttempt(context.Background(), current[0]); err != nil {
+		t.Fatalf("Expected the current owner to save the attempt and received %v", err)
+	}
+}
+
+func TestCreateSubscription_ShouldRejectNonPublicDestinations(t *testing.T) {
+	webhooks := NewService(NewMemoryStore())
+
+	for _, target := range []string{
+		"http://127.0.0.1:8000/hooks",
+		"http://10.0.0.5/hooks",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hooks",
+		"http://[::ffff:192.168.0.1]/hooks",
+		"http://redis:6379",
+		"http://localhost:8080/hooks",
+	} {
+		_, err := webhooks.CreateSubscription(context.Background(), SubscriptionRequest{URL: target})
+		if err == nil || err.Code != http.StatusBadRequest || len(err.Causes) != 1 || err.Causes[0].Code != domain.CodeInvalidURL {
+			t.Fatalf("Expected %s to be rejected as an invalid url and received %+v", target, err)
+		}
+	}
+}
+
+func TestWorker_ShouldRefuseToDialNonPublicAddresses(t *testing.T) {
+	receiver, received := newReceiver(t, http.StatusNoContent)
+	store := NewMemoryStore()
+	webhooks := NewService(store)
+	webhooks.AllowPrivateNetworks = true
+
+	sub, err := webhooks.CreateSubscription(context.Background(), SubscriptionRequest{URL: receiver.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	publishEvent(t, &webhooks, outbox.TaskDeleted, map[string]string{})
+
+	worker := newTestWorker(store)
+	worker.Client = newClient(time.Second, false)
+	worker.deliverDue(context.Background())
+
+	select {
+	case <-received:
+		t.Fatal("Expected the loopback receiver not to be called")
+	default:
+	}
+	deliveries, _ := webhooks.ListDeliveries(context.Background(), sub.ID, 0)
+	if len(deliveries) != 1 || !strings.Contains(deliveries[0].LastError, errNonPublicDestination.Error()) {
+		t.Fatalf("Expected the dial to be refused and received %+v", deliveries)
+	}
+}
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS task_transitions;
+
+UPDATE tasks SET situation = 'in progress' WHERE situation IN ('blocked', 'reopened');
+UPDATE tasks SET situation = 'not started' WHERE situation = 'cancelled';
+
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_situation_check;
+ALTER TABLE tasks ADD CONSTRAINT tasks_situation_check
+    CHECK (situation IN ('in progress', 'completed', 'not started'));
@@ -0,0 +1,14 @@
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_situation_check;
+ALTER TABLE tasks ADD CONSTRAINT tasks_situation_check
+    CHECK (situation IN ('not started', 'in progress', 'completed', 'blocked', 'cancelled', 'reopened'));
+
+CREATE TABLE task_transitions (
+    id UUID PRIMARY KEY,
+    task_id UUID NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    from_situation VARCHAR(20) NOT NULL,
+    to_situation VARCHAR(20) NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_task_transitions_task_id ON task_transitions (task_id, created_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_tasks_situation_created_at_id;
+DROP INDEX IF EXISTS idx_tasks_name_pattern;
+DROP INDEX IF EXISTS idx_tasks_name_id;
+DROP INDEX IF EXISTS idx_tasks_updated_at_id;
+DROP INDEX IF EXISTS idx_tasks_created_at_id;
@@ -0,0 +1,5 @@
+CREATE INDEX idx_tasks_created_at_id ON tasks (created_at, id);
+CREATE INDEX idx_tasks_updated_at_id ON tasks (updated_at, id);
+CREATE INDEX idx_tasks_name_id ON tasks (name, id);
+CREATE INDEX idx_tasks_name_pattern ON tasks (name text_pattern_ops);
+CREATE INDEX idx_tasks_situation_created_at_id ON tasks (situation, created_at, id);
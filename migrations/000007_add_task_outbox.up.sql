@@ -0,0 +1,14 @@
+CREATE TABLE task_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL UNIQUE,
+    aggregate_id UUID NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMP WITH TIME ZONE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT
+);
+
+CREATE INDEX idx_task_outbox_unpublished ON task_outbox (id) WHERE published_at IS NULL;
+CREATE INDEX idx_task_outbox_published_at ON task_outbox (published_at) WHERE published_at IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_tasks_due_at;
+DROP INDEX IF EXISTS idx_tasks_priority_rank_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS priority_rank;
+ALTER TABLE tasks DROP COLUMN IF EXISTS due_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS priority;
@@ -0,0 +1,10 @@
+ALTER TABLE tasks
+    ADD COLUMN priority VARCHAR(10) NOT NULL DEFAULT 'medium' CHECK (priority IN ('low', 'medium', 'high', 'urgent')),
+    ADD COLUMN due_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE tasks ADD COLUMN priority_rank SMALLINT GENERATED ALWAYS AS (
+    CASE priority WHEN 'low' THEN 1 WHEN 'medium' THEN 2 WHEN 'high' THEN 3 WHEN 'urgent' THEN 4 END
+) STORED;
+
+CREATE INDEX idx_tasks_priority_rank_id ON tasks (priority_rank, id);
+CREATE INDEX idx_tasks_due_at ON tasks (due_at) WHERE due_at IS NOT NULL;